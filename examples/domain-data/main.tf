@@ -0,0 +1,16 @@
+data "qiniu_cdn_domain" "domain" {
+  name = var.domain_name
+}
+
+data "qiniu_cdn_domains" "domains" {
+  platform = "web"
+  protocol = "https"
+}
+
+output "cname" {
+  value = data.qiniu_cdn_domain.domain.cname
+}
+
+output "domains" {
+  value = data.qiniu_cdn_domains.domains.names
+}
@@ -0,0 +1 @@
+variable "domain_name" {}
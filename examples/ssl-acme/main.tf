@@ -0,0 +1,34 @@
+# 证书临近过期时会被替换, create_before_destroy保证加速域名切换到新证书后才删除旧证书
+resource "qiniu_ssl_cert_acme" "cert" {
+  email = var.email
+  domains = [var.domain_name]
+  bucket = var.qiniu_bucket
+  min_days_remaining = 30
+
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+
+resource "qiniu_cdn_domain" "domain" {
+  name = var.domain_name
+  type = "normal"
+  platform = "web"
+  geo_cover = "global"
+  protocol = "https"
+
+  source {
+    type = "qiniuBucket"
+    qiniu_bucket = var.qiniu_bucket
+  }
+
+  https {
+    cert_id = qiniu_ssl_cert_acme.cert.cert_id
+    force = true
+    http2 = true
+  }
+}
+
+output "cert_id" {
+  value = qiniu_ssl_cert_acme.cert.cert_id
+}
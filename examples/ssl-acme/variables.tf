@@ -0,0 +1,5 @@
+variable "email" {}
+
+variable "domain_name" {}
+
+variable "qiniu_bucket" {}
@@ -0,0 +1,3 @@
+terraform {
+  required_version = ">= 0.14"
+}
\ No newline at end of file
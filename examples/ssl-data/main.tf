@@ -0,0 +1,7 @@
+data "qiniu_ssl_certs" "expiring" {
+  expiring_within_days = 30
+}
+
+output "expiring" {
+  value = data.qiniu_ssl_certs.expiring.certs
+}
@@ -0,0 +1,9 @@
+terraform {
+  required_providers {
+    qiniu = {
+      source = "bingtsingw/qiniu"
+    }
+  }
+}
+
+provider "qiniu" {}
@@ -0,0 +1,28 @@
+resource "qiniu_ssl_cert" "cert" {
+  name_prefix = "${var.domain_name}-"
+  ca = file(var.cert_file)
+  pri = file(var.key_file)
+
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+
+resource "qiniu_cdn_domain" "domain" {
+  name = var.domain_name
+  type = "normal"
+  platform = "web"
+  geo_cover = "global"
+  protocol = "https"
+
+  source {
+    type = "qiniuBucket"
+    qiniu_bucket = var.qiniu_bucket
+  }
+
+  https {
+    cert_id = qiniu_ssl_cert.cert.id
+    force = true
+    http2 = true
+  }
+}
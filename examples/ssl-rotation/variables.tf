@@ -0,0 +1,7 @@
+variable "domain_name" {}
+
+variable "qiniu_bucket" {}
+
+variable "cert_file" {}
+
+variable "key_file" {}
@@ -3,6 +3,8 @@ module github.com/bingtsingw/terraform-provider-qiniu
 go 1.16
 
 require (
+	github.com/hashicorp/go-cty v1.4.1-0.20200414143053-d3edf31b6320
 	github.com/hashicorp/terraform-plugin-sdk/v2 v2.4.4
 	github.com/qiniu/go-sdk/v7 v7.9.1
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
 )
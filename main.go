@@ -6,10 +6,13 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
 )
 
+// version 在发布时通过 -ldflags "-X main.version=..." 注入
+var version = "dev"
+
 func main() {
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: func() *schema.Provider {
-			return qiniu.Provider()
+			return qiniu.Provider(version)
 		},
 	})
 }
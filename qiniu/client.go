@@ -3,11 +3,11 @@ package qiniu
 import (
 	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/cert"
 	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/domain"
-	"github.com/qiniu/go-sdk/v7/storage"
+	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/kodo"
 )
 
 type Client struct {
-	bucketconn *storage.BucketManager
+	bucketconn *kodo.BucketManager
 	certconn   *cert.CertManager
 	domainconn *domain.DomainManager
 }
@@ -1,25 +1,161 @@
 package qiniu
 
 import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
 	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/cert"
 	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/domain"
+	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/kodo"
+	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/transport"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/logging"
 	"github.com/qiniu/go-sdk/v7/auth"
+	"github.com/qiniu/go-sdk/v7/client"
 	"github.com/qiniu/go-sdk/v7/storage"
 )
 
+const (
+	apiFamilyFusion  = "fusion"
+	apiFamilyUc      = "uc"
+	apiFamilyRs      = "rs"
+	apiFamilySslcert = "sslcert"
+)
+
 type Config struct {
 	AccessKey string
 	SecretKey string
+
+	ApiHost  string
+	UcHost   string
+	RsHost   string
+	RsfHost  string
+	UpHost   string
+	UseHttps bool
+
+	MaxRetries int
+
+	// 各类接口每秒最多发起的请求数, 0表示不限制
+	FusionRateLimit  float64
+	UcRateLimit      float64
+	RsRateLimit      float64
+	SslcertRateLimit float64
+
+	ProviderVersion  string
+	TerraformVersion string
+	UserAgentSuffix  string
 }
 
 func (c *Config) Client() Client {
 	credentials := auth.New(c.AccessKey, c.SecretKey)
 
+	// TF_LOG为DEBUG或TRACE时记录七牛接口的请求, 放在重试内层以便记录每一次尝试
+	var roundTripper http.RoundTripper = http.DefaultTransport
+	if logging.IsDebugOrHigher() {
+		roundTripper = transport.NewLoggingTransport(roundTripper, logging.LogLevel() == "TRACE")
+	}
+
+	// 限流放在重试内层, 使每一次重试同样受限
+	roundTripper = transport.NewRateLimitTransport(roundTripper, map[string]*transport.RateLimiter{
+		apiFamilyFusion:  transport.NewRateLimiter(c.FusionRateLimit, 0),
+		apiFamilyUc:      transport.NewRateLimiter(c.UcRateLimit, 0),
+		apiFamilyRs:      transport.NewRateLimiter(c.RsRateLimit, 0),
+		apiFamilySslcert: transport.NewRateLimiter(c.SslcertRateLimit, 0),
+	}, c.apiFamily)
+	roundTripper = transport.NewRetryTransport(roundTripper, c.MaxRetries)
+	roundTripper = transport.NewUserAgentTransport(roundTripper, c.userAgent())
+
+	// 所有manager共用同一个http client, 以便共享重试等设置
+	httpClient := &client.Client{
+		Client: &http.Client{
+			Transport: roundTripper,
+		},
+	}
+
+	storageConfig := &storage.Config{
+		UseHTTPS: c.UseHttps,
+	}
+
+	// 指定rs_host或rsf_host时不再根据bucket查询所在区域, 上传地址由up_host单独指定, 未指定时仍按bucket查询
+	if c.RsHost != "" || c.RsfHost != "" {
+		storageConfig.Zone = &storage.Region{
+			RsHost:  c.RsHost,
+			RsfHost: c.RsfHost,
+			ApiHost: c.ApiHost,
+		}
+
+		if c.UpHost != "" {
+			storageConfig.Zone.SrcUpHosts = []string{c.UpHost}
+			storageConfig.Zone.CdnUpHosts = []string{c.UpHost}
+		}
+	}
+
+	if c.RsHost != "" {
+		storageConfig.CentralRsHost = c.RsHost
+	}
+
 	client := Client{
-		bucketconn: storage.NewBucketManager(credentials, nil),
-		certconn:   cert.NewCertManager(credentials),
-		domainconn: domain.NewDomainManager(credentials),
+		bucketconn: kodo.NewBucketManager(credentials, storageConfig, c.endpoint(c.UcHost), c.endpoint(c.UpHost), httpClient),
+		certconn:   cert.NewCertManager(credentials, c.endpoint(c.ApiHost), httpClient),
+		domainconn: domain.NewDomainManager(credentials, c.endpoint(c.ApiHost), httpClient),
 	}
 
 	return client
 }
+
+// endpoint 为未指定协议的host按照use_https补全协议
+func (c *Config) endpoint(host string) string {
+	if host == "" || strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
+		return host
+	}
+
+	if c.UseHttps {
+		return "https://" + host
+	}
+
+	return "http://" + host
+}
+
+// userAgent 返回形如terraform-provider-qiniu/<version> terraform/<tf version>的User-Agent
+func (c *Config) userAgent() string {
+	providerVersion := c.ProviderVersion
+	if providerVersion == "" {
+		providerVersion = "dev"
+	}
+
+	// terraform 0.12之前不会向provider传递版本号
+	terraformVersion := c.TerraformVersion
+	if terraformVersion == "" {
+		terraformVersion = "0.11+compatible"
+	}
+
+	userAgent := fmt.Sprintf("terraform-provider-qiniu/%s terraform/%s", providerVersion, terraformVersion)
+
+	if suffix := strings.TrimSpace(c.UserAgentSuffix); suffix != "" {
+		userAgent += " " + suffix
+	}
+
+	return userAgent
+}
+
+// apiFamily 根据请求的host和path判断所属的接口类别, 用于选择限流器
+func (c *Config) apiFamily(req *http.Request) string {
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/sslcert"):
+		return apiFamilySslcert
+	case strings.HasPrefix(req.URL.Path, "/domain"):
+		return apiFamilyFusion
+	}
+
+	ucHost := c.endpoint(c.UcHost)
+	if ucHost == "" {
+		ucHost = kodo.DefaultUcHost
+	}
+	if u, err := url.Parse(ucHost); err == nil && u.Host == req.URL.Host {
+		return apiFamilyUc
+	}
+
+	// 其余请求均为bucket及文件管理相关的rs/rsf接口
+	return apiFamilyRs
+}
@@ -0,0 +1,273 @@
+package qiniu
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/cert"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/qiniu/go-sdk/v7/client"
+)
+
+const (
+	defaultCredentialsProfile = "default"
+	defaultCredentialsFile    = "~/.qiniu/credentials"
+	defaultQshellAccountFile  = "~/.qshell/account.json"
+)
+
+// resolveCredentials 按照 provider配置/环境变量 > 共享凭证文件 > qshell账号 的顺序查找AK/SK,
+// 显式指定profile时profile优先于环境变量中的AK/SK
+func resolveCredentials(accessKey, secretKey, profile, credentialsFile string) (string, string, diag.Diagnostics) {
+	explicitProfile := profile != ""
+
+	if accessKey != "" || secretKey != "" {
+		fromEnv := accessKey == os.Getenv("QINIU_ACCESS_KEY") && secretKey == os.Getenv("QINIU_SECRET_KEY")
+
+		switch {
+		case explicitProfile && !fromEnv:
+			return "", "", diag.Errorf("access_key/secret_key and profile %q can not be set at the same time", profile)
+		case explicitProfile:
+			// 忽略环境变量中的AK/SK, 使用指定的profile
+		case accessKey == "" || secretKey == "":
+			return "", "", diag.Errorf("access_key and secret_key must be set together")
+		default:
+			return accessKey, secretKey, nil
+		}
+	}
+
+	if !explicitProfile {
+		profile = defaultCredentialsProfile
+	}
+
+	if credentialsFile == "" {
+		credentialsFile = defaultCredentialsFile
+	}
+
+	path, err := expandHomeDir(credentialsFile)
+	if err != nil {
+		return "", "", diag.FromErr(err)
+	}
+
+	profiles, err := parseCredentialsFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", "", diag.Errorf("failed to read shared credentials file %s: %s", path, err)
+	}
+
+	if p, ok := profiles[profile]; ok {
+		if p["access_key"] == "" || p["secret_key"] == "" {
+			return "", "", diag.Errorf("profile %q in %s must set both access_key and secret_key", profile, path)
+		}
+		return p["access_key"], p["secret_key"], nil
+	}
+
+	if explicitProfile {
+		return "", "", diag.Errorf("profile %q not found in shared credentials file %s", profile, path)
+	}
+
+	accessKey, secretKey, diags := readQshellAccount()
+	if accessKey != "" {
+		return accessKey, secretKey, diags
+	}
+
+	return "", "", append(diags, diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  "No Qiniu credentials found",
+		Detail: fmt.Sprintf("Set access_key/secret_key in the provider block, the QINIU_ACCESS_KEY/QINIU_SECRET_KEY "+
+			"environment variables, a [%s] profile in %s, or log in with qshell.", profile, path),
+	})
+}
+
+// parseCredentialsFile 解析INI格式的共享凭证文件, 返回 profile -> key -> value
+func parseCredentialsFile(path string) (map[string]map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	profiles := map[string]map[string]string{}
+	var current map[string]string
+
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			current = map[string]string{}
+			profiles[name] = current
+			continue
+		}
+
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 || current == nil {
+			return nil, fmt.Errorf("invalid line %d", lineNo)
+		}
+
+		current[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	return profiles, scanner.Err()
+}
+
+// readQshellAccount 读取qshell保存的账号信息, 未找到可用账号时accessKey为空
+func readQshellAccount() (accessKey, secretKey string, diags diag.Diagnostics) {
+	path, err := expandHomeDir(defaultQshellAccountFile)
+	if err != nil {
+		return "", "", nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", "", nil
+	}
+
+	var account struct {
+		AccessKey string `json:"access_key"`
+		SecretKey string `json:"secret_key"`
+	}
+
+	if err := json.Unmarshal(content, &account); err != nil || account.AccessKey == "" || account.SecretKey == "" {
+		return "", "", nil
+	}
+
+	// 旧版本qshell保存明文secret_key, 新版本保存加密后的secret_key
+	if isPlainQiniuKey(account.SecretKey) {
+		return account.AccessKey, account.SecretKey, nil
+	}
+
+	secretKey, err = decryptQshellSecretKey(account.AccessKey, account.SecretKey)
+	if err != nil || !isPlainQiniuKey(secretKey) {
+		return "", "", diag.Diagnostics{
+			{
+				Severity: diag.Warning,
+				Summary:  "Ignoring qshell account",
+				Detail:   fmt.Sprintf("The secret_key in %s is neither a plaintext key nor in a format this provider can decrypt.", path),
+			},
+		}
+	}
+
+	return account.AccessKey, secretKey, nil
+}
+
+// decryptQshellSecretKey 解密qshell保存的secret_key, qshell使用access_key的前16个字节作为密钥和IV,
+// 以AES-CBC和PKCS5填充加密后再进行URL安全的base64编码
+func decryptQshellSecretKey(accessKey, encrypted string) (string, error) {
+	if len(accessKey) < aes.BlockSize {
+		return "", errors.New("access_key is too short")
+	}
+
+	data, err := base64.URLEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return "", errors.New("invalid encrypted secret_key length")
+	}
+
+	key := []byte(accessKey[:aes.BlockSize])
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, key).CryptBlocks(plain, data)
+
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return "", errors.New("invalid padding")
+	}
+	for _, b := range plain[len(plain)-padding:] {
+		if int(b) != padding {
+			return "", errors.New("invalid padding")
+		}
+	}
+
+	return string(plain[:len(plain)-padding]), nil
+}
+
+// isPlainQiniuKey 判断key是否为明文的AK/SK, 七牛的AK/SK均为40位URL安全的base64字符
+func isPlainQiniuKey(key string) bool {
+	if len(key) != 40 {
+		return false
+	}
+
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func expandHomeDir(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, path[1:]), nil
+}
+
+// validateCredentials 发起一次开销很小的鉴权请求, 将常见的鉴权失败转换为可操作的提示
+func validateCredentials(ctx context.Context, c Client) diag.Diagnostics {
+	err := c.certconn.ListCerts(ctx, cert.ListOptions{Limit: 1}, func(_ []cert.CertInfo) bool {
+		return false
+	})
+	if err == nil {
+		return nil
+	}
+
+	var e *client.ErrorInfo
+	if !errors.As(err, &e) {
+		return diag.Errorf("failed to validate Qiniu credentials: %s", err)
+	}
+
+	message := strings.ToLower(e.Err)
+
+	switch {
+	// 被禁用的账号同样可能返回401, 需要先根据错误信息判断
+	case strings.Contains(message, "disabled") || strings.Contains(message, "frozen"):
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Qiniu account is disabled",
+			Detail:   fmt.Sprintf("The account owning this key is disabled or frozen, contact Qiniu support. API error: %s", e.Err),
+		}}
+	case e.Code == 401:
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Invalid Qiniu access key or secret key",
+			Detail:   fmt.Sprintf("Check access_key/secret_key, QINIU_ACCESS_KEY/QINIU_SECRET_KEY or the selected profile. API error: %s", e.Err),
+		}}
+	case e.Code == 403:
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Qiniu key lacks permission",
+			Detail: fmt.Sprintf("The key is valid but not authorized to call the SSL certificate API. If it belongs to a sub-account, "+
+				"grant it the required permissions or set skip_credentials_validation = true. API error: %s", e.Err),
+		}}
+	default:
+		return diag.Errorf("failed to validate Qiniu credentials: %s", e.ErrorDetail())
+	}
+}
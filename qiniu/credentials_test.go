@@ -0,0 +1,292 @@
+package qiniu
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	testAccessKey = "AKtestAKtestAKtestAKtestAKtestAKtest-_00"
+	testSecretKey = "SKtestSKtestSKtestSKtestSKtestSKtest-_00"
+)
+
+func writeCredentialsFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "credentials")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func setEnv(t *testing.T, key, value string) {
+	t.Helper()
+
+	old, ok := os.LookupEnv(key)
+	os.Setenv(key, value)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestParseCredentialsFile(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		want    map[string]map[string]string
+		wantErr string
+	}{
+		{
+			name: "profiles",
+			content: `
+# comment
+[default]
+access_key = ak1
+secret_key = sk1
+
+; another comment
+[ work ]
+access_key=ak2
+secret_key=sk=2
+`,
+			want: map[string]map[string]string{
+				"default": {"access_key": "ak1", "secret_key": "sk1"},
+				"work":    {"access_key": "ak2", "secret_key": "sk=2"},
+			},
+		},
+		{
+			name:    "line without value",
+			content: "[default]\naccess_key\n",
+			wantErr: "invalid line 2",
+		},
+		{
+			name:    "key outside profile",
+			content: "access_key = ak1\n[default]\n",
+			wantErr: "invalid line 1",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			profiles, err := parseCredentialsFile(writeCredentialsFile(t, c.content))
+
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("expected error %q, got %v", c.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(profiles, c.want) {
+				t.Errorf("expected %v, got %v", c.want, profiles)
+			}
+		})
+	}
+}
+
+func TestResolveCredentials(t *testing.T) {
+	path := writeCredentialsFile(t, "[default]\naccess_key = ak1\nsecret_key = sk1\n[work]\naccess_key = ak2\nsecret_key = sk2\n")
+
+	cases := []struct {
+		name       string
+		env        map[string]string
+		accessKey  string
+		secretKey  string
+		profile    string
+		wantAccess string
+		wantSecret string
+		wantErr    string
+	}{
+		{
+			name:       "default profile",
+			wantAccess: "ak1",
+			wantSecret: "sk1",
+		},
+		{
+			name:       "named profile",
+			profile:    "work",
+			wantAccess: "ak2",
+			wantSecret: "sk2",
+		},
+		{
+			name:    "missing profile",
+			profile: "missing",
+			wantErr: `profile "missing" not found`,
+		},
+		{
+			name:       "keys in provider config",
+			accessKey:  "ak3",
+			secretKey:  "sk3",
+			wantAccess: "ak3",
+			wantSecret: "sk3",
+		},
+		{
+			name:       "profile wins over environment",
+			env:        map[string]string{"QINIU_ACCESS_KEY": "ak3", "QINIU_SECRET_KEY": "sk3"},
+			accessKey:  "ak3",
+			secretKey:  "sk3",
+			profile:    "work",
+			wantAccess: "ak2",
+			wantSecret: "sk2",
+		},
+		{
+			name:      "profile conflicts with provider config",
+			accessKey: "ak3",
+			secretKey: "sk3",
+			profile:   "work",
+			wantErr:   "can not be set at the same time",
+		},
+		{
+			name:      "secret key missing",
+			accessKey: "ak3",
+			wantErr:   "must be set together",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			setEnv(t, "QINIU_ACCESS_KEY", c.env["QINIU_ACCESS_KEY"])
+			setEnv(t, "QINIU_SECRET_KEY", c.env["QINIU_SECRET_KEY"])
+
+			accessKey, secretKey, diags := resolveCredentials(c.accessKey, c.secretKey, c.profile, path)
+
+			if c.wantErr != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Summary, c.wantErr) {
+					t.Fatalf("expected error %q, got %v", c.wantErr, diags)
+				}
+				return
+			}
+
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+			if accessKey != c.wantAccess || secretKey != c.wantSecret {
+				t.Errorf("expected %s/%s, got %s/%s", c.wantAccess, c.wantSecret, accessKey, secretKey)
+			}
+		})
+	}
+}
+
+// encryptQshellSecretKey 按照qshell的方式加密secret_key
+func encryptQshellSecretKey(t *testing.T, accessKey string, plain []byte) string {
+	t.Helper()
+
+	key := []byte(accessKey[:aes.BlockSize])
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, key).CryptBlocks(encrypted, plain)
+
+	return base64.URLEncoding.EncodeToString(encrypted)
+}
+
+func pkcs5Padding(data []byte) []byte {
+	padding := aes.BlockSize - len(data)%aes.BlockSize
+	return append(data, bytes.Repeat([]byte{byte(padding)}, padding)...)
+}
+
+func TestDecryptQshellSecretKey(t *testing.T) {
+	cases := []struct {
+		name      string
+		accessKey string
+		encrypted string
+		want      string
+		wantErr   string
+	}{
+		{
+			name:      "round trip",
+			accessKey: testAccessKey,
+			encrypted: encryptQshellSecretKey(t, testAccessKey, pkcs5Padding([]byte(testSecretKey))),
+			want:      testSecretKey,
+		},
+		{
+			name:      "access key too short",
+			accessKey: "short",
+			encrypted: "AAAA",
+			wantErr:   "too short",
+		},
+		{
+			name:      "invalid base64",
+			accessKey: testAccessKey,
+			encrypted: "not base64!",
+			wantErr:   "illegal base64",
+		},
+		{
+			name:      "invalid length",
+			accessKey: testAccessKey,
+			encrypted: base64.URLEncoding.EncodeToString([]byte("short")),
+			wantErr:   "invalid encrypted secret_key length",
+		},
+		{
+			name:      "zero padding",
+			accessKey: testAccessKey,
+			encrypted: encryptQshellSecretKey(t, testAccessKey, make([]byte, aes.BlockSize)),
+			wantErr:   "invalid padding",
+		},
+		{
+			name:      "inconsistent padding",
+			accessKey: testAccessKey,
+			encrypted: encryptQshellSecretKey(t, testAccessKey, append(bytes.Repeat([]byte{'a'}, 13), 1, 2, 3)),
+			wantErr:   "invalid padding",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := decryptQshellSecretKey(c.accessKey, c.encrypted)
+
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("expected error %q, got %v", c.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != c.want {
+				t.Errorf("expected %q, got %q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestIsPlainQiniuKey(t *testing.T) {
+	cases := []struct {
+		key  string
+		want bool
+	}{
+		{testSecretKey, true},
+		{testSecretKey[:39], false},
+		{testSecretKey + "0", false},
+		{strings.Repeat("a", 39) + "+", false},
+		{encryptQshellSecretKey(t, testAccessKey, pkcs5Padding([]byte(testSecretKey))), false},
+	}
+
+	for _, c := range cases {
+		if got := isPlainQiniuKey(c.key); got != c.want {
+			t.Errorf("isPlainQiniuKey(%q) = %v, want %v", c.key, got, c.want)
+		}
+	}
+}
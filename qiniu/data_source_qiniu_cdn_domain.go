@@ -0,0 +1,172 @@
+package qiniu
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceQiniuCdnDomain() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceQiniuCdnDomainRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"cname": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"platform": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"geo_cover": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"protocol": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"https": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cert_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"force": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"http2": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"source": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"host": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"follow_request_host": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"origin_sni": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ips": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"domain": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"qiniu_bucket": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"url_scheme": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"test_url_path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"advanced_source": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"addr": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"weight": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"backup": {
+										Type:     schema.TypeBool,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceQiniuCdnDomainRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	conn := meta.(Client).domainconn
+	domainName := d.Get("name").(string)
+
+	res, err := conn.GetDomainInfo(ctx, domainName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("cname", res.CName); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("type", res.Type); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("platform", res.Platform); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("geo_cover", res.GeoCover); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("protocol", res.Protocol); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("source", flattenResponseDomainSource(res.Source)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if res.Protocol == "https" {
+		if err := d.Set("https", flattenResponseDomainHttps(res.Https)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId(domainName)
+
+	return diags
+}
@@ -0,0 +1,157 @@
+package qiniu
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceQiniuCdnDomains() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceQiniuCdnDomainsRead,
+		Schema: map[string]*schema.Schema{
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"normal", "wildcard"}, false),
+			},
+			"platform": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"web", "download", "vod", "dynamic"}, false),
+			},
+			"protocol": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"http", "https"}, false),
+			},
+			"source_qiniu_bucket": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"domains": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"cname": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"platform": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"geo_cover": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"protocol": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"source_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"source_qiniu_bucket": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceQiniuCdnDomainsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	conn := meta.(Client).domainconn
+
+	var nameRegex *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		nameRegex = regexp.MustCompile(v.(string))
+	}
+
+	domainInfos, err := conn.GetDomainsInfo(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	names := make([]string, 0, len(domainInfos))
+	domains := make([]map[string]interface{}, 0, len(domainInfos))
+	for _, info := range domainInfos {
+		if nameRegex != nil && !nameRegex.MatchString(info.Name) {
+			continue
+		}
+
+		if v, ok := d.GetOk("type"); ok && info.Type != v.(string) {
+			continue
+		}
+
+		if v, ok := d.GetOk("platform"); ok && info.Platform != v.(string) {
+			continue
+		}
+
+		if v, ok := d.GetOk("protocol"); ok && info.Protocol != v.(string) {
+			continue
+		}
+
+		if v, ok := d.GetOk("source_qiniu_bucket"); ok && info.SourceQiniuBucket != v.(string) {
+			continue
+		}
+
+		attributes := map[string]interface{}{
+			"name":                info.Name,
+			"cname":               info.CName,
+			"type":                info.Type,
+			"platform":            info.Platform,
+			"geo_cover":           info.GeoCover,
+			"protocol":            info.Protocol,
+			"source_type":         info.SourceType,
+			"source_qiniu_bucket": info.SourceQiniuBucket,
+		}
+
+		names = append(names, info.Name)
+		domains = append(domains, attributes)
+	}
+
+	if err := d.Set("names", names); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("domains", domains); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+
+	return diags
+}
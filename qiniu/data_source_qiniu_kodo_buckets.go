@@ -52,13 +52,13 @@ func dataSourceQiniuKodoBuckets() *schema.Resource {
 	}
 }
 
-func dataSourceQiniuKodoBucketsRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func dataSourceQiniuKodoBucketsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 
 	conn := meta.(Client).bucketconn
 	regionId := storage.RegionID(d.Get("region_id").(string))
 
-	bucketInfos, err := conn.BucketInfosInRegion(regionId, false)
+	bucketInfos, err := conn.BucketInfosInRegion(ctx, regionId, false)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -0,0 +1,158 @@
+package qiniu
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/cert"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceQiniuSslCerts() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceQiniuSslCertsRead,
+		Schema: map[string]*schema.Schema{
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"common_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"dns_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			// 只返回在指定天数内过期(包括已过期)的证书
+			"expiring_within_days": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"certs": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"common_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"dns_names": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"not_before": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"not_after": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceQiniuSslCertsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	conn := meta.(Client).certconn
+
+	var nameRegex *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		nameRegex = regexp.MustCompile(v.(string))
+	}
+
+	var expiringBefore time.Time
+	days, expiring := d.GetOk("expiring_within_days")
+	if expiring {
+		expiringBefore = time.Now().AddDate(0, 0, days.(int))
+	}
+
+	certInfos, err := conn.GetCertsInfo(ctx)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ids := make([]string, 0, len(certInfos))
+	certs := make([]map[string]interface{}, 0, len(certInfos))
+	for _, c := range certInfos {
+		if nameRegex != nil && !nameRegex.MatchString(c.Name) {
+			continue
+		}
+
+		if v, ok := d.GetOk("common_name"); ok && c.CommonName != v.(string) {
+			continue
+		}
+
+		if v, ok := d.GetOk("dns_name"); ok && !certHasDnsName(c, v.(string)) {
+			continue
+		}
+
+		if expiring && time.Unix(c.NotAfter, 0).After(expiringBefore) {
+			continue
+		}
+
+		attributes := map[string]interface{}{
+			"id":          c.Id,
+			"name":        c.Name,
+			"common_name": c.CommonName,
+			"dns_names":   c.DnsNames,
+			"not_before":  time.Unix(c.NotBefore, 0).UTC().Format(time.RFC3339),
+			"not_after":   time.Unix(c.NotAfter, 0).UTC().Format(time.RFC3339),
+		}
+
+		ids = append(ids, c.Id)
+		certs = append(certs, attributes)
+	}
+
+	if err := d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("certs", certs); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+
+	return diags
+}
+
+func certHasDnsName(c cert.CertInfo, name string) bool {
+	for _, n := range c.DnsNames {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -4,40 +4,169 @@ import (
 	"context"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
-func Provider() *schema.Provider {
+func Provider(version string) *schema.Provider {
 	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"access_key": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("QINIU_ACCESS_KEY", ""),
 			},
 			"secret_key": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Sensitive:   true,
 				DefaultFunc: schema.EnvDefaultFunc("QINIU_SECRET_KEY", ""),
 			},
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("QINIU_PROFILE", ""),
+			},
+			"shared_credentials_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("QINIU_SHARED_CREDENTIALS_FILE", ""),
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("QINIU_MAX_RETRIES", 3),
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"skip_credentials_validation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("QINIU_SKIP_CREDENTIALS_VALIDATION", false),
+			},
+			"api_host": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("QINIU_API_HOST", "api.qiniu.com"),
+			},
+			"uc_host": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("QINIU_UC_HOST", "uc.qbox.me"),
+			},
+			// rs_host、rsf_host和up_host为空时根据bucket所在区域自动选择
+			"rs_host": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("QINIU_RS_HOST", ""),
+			},
+			"rsf_host": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("QINIU_RSF_HOST", ""),
+			},
+			"up_host": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("QINIU_UP_HOST", ""),
+			},
+			"use_https": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("QINIU_USE_HTTPS", true),
+			},
+			// 各类接口每秒最多发起的请求数, 0表示不限制, 所有资源共享同一组限流器
+			"fusion_rate_limit": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("QINIU_FUSION_RATE_LIMIT", 5),
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+			"uc_rate_limit": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("QINIU_UC_RATE_LIMIT", 0),
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+			"rs_rate_limit": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("QINIU_RS_RATE_LIMIT", 0),
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+			"sslcert_rate_limit": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("QINIU_SSLCERT_RATE_LIMIT", 0),
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+			"user_agent_suffix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("QINIU_USER_AGENT_SUFFIX", ""),
+			},
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			"qiniu_kodo_buckets": dataSourceQiniuKodoBuckets(),
+			"qiniu_cdn_domain":   dataSourceQiniuCdnDomain(),
+			"qiniu_cdn_domains":  dataSourceQiniuCdnDomains(),
+			"qiniu_ssl_certs":    dataSourceQiniuSslCerts(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"qiniu_ssl_cert":   resourceQiniuSslCert(),
-			"qiniu_cdn_domain": resourceQiniuCdnDomain(),
+			"qiniu_ssl_cert":       resourceQiniuSslCert(),
+			"qiniu_ssl_cert_acme":  resourceQiniuSslCertAcme(),
+			"qiniu_ssl_cert_order": resourceQiniuSslCertOrder(),
+			"qiniu_cdn_domain":     resourceQiniuCdnDomain(),
 		},
-		ConfigureContextFunc: providerConfigure,
+	}
+
+	provider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+		return providerConfigure(ctx, d, version, provider.TerraformVersion)
 	}
 
 	return provider
 }
 
-func providerConfigure(_ context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+func providerConfigure(ctx context.Context, d *schema.ResourceData, providerVersion, terraformVersion string) (interface{}, diag.Diagnostics) {
+	accessKey, secretKey, diags := resolveCredentials(
+		d.Get("access_key").(string),
+		d.Get("secret_key").(string),
+		d.Get("profile").(string),
+		d.Get("shared_credentials_file").(string),
+	)
+	if diags.HasError() {
+		return nil, diags
+	}
+
 	config := Config{
-		AccessKey: d.Get("access_key").(string),
-		SecretKey: d.Get("secret_key").(string),
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+
+		ApiHost:  d.Get("api_host").(string),
+		UcHost:   d.Get("uc_host").(string),
+		RsHost:   d.Get("rs_host").(string),
+		RsfHost:  d.Get("rsf_host").(string),
+		UpHost:   d.Get("up_host").(string),
+		UseHttps: d.Get("use_https").(bool),
+
+		MaxRetries: d.Get("max_retries").(int),
+
+		FusionRateLimit:  d.Get("fusion_rate_limit").(float64),
+		UcRateLimit:      d.Get("uc_rate_limit").(float64),
+		RsRateLimit:      d.Get("rs_rate_limit").(float64),
+		SslcertRateLimit: d.Get("sslcert_rate_limit").(float64),
+
+		ProviderVersion:  providerVersion,
+		TerraformVersion: terraformVersion,
+		UserAgentSuffix:  d.Get("user_agent_suffix").(string),
+	}
+
+	client := config.Client()
+
+	if !d.Get("skip_credentials_validation").(bool) {
+		diags = append(diags, validateCredentials(ctx, client)...)
+		if diags.HasError() {
+			return nil, diags
+		}
 	}
 
-	return config.Client(), nil
+	return client, diags
 }
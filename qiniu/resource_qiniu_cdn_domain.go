@@ -21,6 +21,7 @@ func resourceQiniuCdnDomain() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: resourceQiniuCdnDomainCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -41,13 +42,13 @@ func resourceQiniuCdnDomain() *schema.Resource {
 			"platform": {
 				Type:         schema.TypeString,
 				Required:     true,
-				ForceNew:     true,
+				ForceNew:     false,
 				ValidateFunc: validation.StringInSlice([]string{"web", "download", "vod", "dynamic"}, false),
 			},
 			"geo_cover": {
 				Type:         schema.TypeString,
 				Required:     true,
-				ForceNew:     true,
+				ForceNew:     false,
 				ValidateFunc: validation.StringInSlice([]string{"china", "foreign", "global"}, false),
 			},
 			"protocol": {
@@ -89,11 +90,21 @@ func resourceQiniuCdnDomain() *schema.Resource {
 							Required:     true,
 							ValidateFunc: validation.StringInSlice([]string{"domain", "ip", "qiniuBucket", "advanced"}, false),
 						},
-						// 后台没有该设置选项, [API](https://developer.qiniu.com/fusion/4249/product-features#2)也不清晰
-						//"host": {
-						//	Type:     schema.TypeString,
-						//	Required: true,
-						//},
+						// 回源HOST, 为空时使用源站默认值
+						"host": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						// 回源HOST跟随请求的加速域名, 与host互斥
+						"follow_request_host": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"origin_sni": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
 						"ips": {
 							Type:     schema.TypeList,
 							Optional: true,
@@ -119,6 +130,29 @@ func resourceQiniuCdnDomain() *schema.Resource {
 							Optional: true,
 							Default:  "qiniu_do_not_delete.gif",
 						},
+						"advanced_source": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"addr": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+									"weight": {
+										Type:         schema.TypeInt,
+										Optional:     true,
+										Default:      1,
+										ValidateFunc: validation.IntBetween(1, 100),
+									},
+									"backup": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  false,
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -166,6 +200,93 @@ func resourceQiniuCdnDomain() *schema.Resource {
 					},
 				},
 			},
+			// 分片回源, 仅download和vod平台支持
+			"range_origin": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"follow_redirect": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			// 回源超时时间, 单位为秒
+			"origin_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntBetween(1, 300),
+			},
+			"compress": {
+				Type:     schema.TypeSet,
+				MaxItems: 1,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:     schema.TypeBool,
+							Required: true,
+						},
+						"file_types": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+			"image_slim": {
+				Type:     schema.TypeSet,
+				MaxItems: 1,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:     schema.TypeBool,
+							Required: true,
+						},
+						"prefixes": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"regexes": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+			"response_headers": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"value": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"operation": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "set",
+							ValidateFunc: validation.StringInSlice([]string{"set", "delete"}, false),
+						},
+					},
+				},
+			},
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(30 * time.Minute),
@@ -175,13 +296,41 @@ func resourceQiniuCdnDomain() *schema.Resource {
 	}
 }
 
-func resourceQiniuCdnDomainRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+func resourceQiniuCdnDomainCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.NewValueKnown("source") {
+		source := convertInputDomainSource(d.Get("source").(*schema.Set).List())
+		if source.FollowHost && source.Host != "" {
+			return fmt.Errorf("source host and follow_request_host can not be set at the same time")
+		}
+	}
+
+	if !d.NewValueKnown("platform") {
+		return nil
+	}
+
+	platform := d.Get("platform").(string)
+
+	if d.Get("range_origin").(bool) && platform != "download" && platform != "vod" {
+		return fmt.Errorf("range_origin is only supported on 'download' and 'vod' platforms, got %q", platform)
+	}
+
+	return nil
+}
+
+func resourceQiniuCdnDomainRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	conn := m.(Client).domainconn
 	domainName := d.Id()
 
-	res, err := conn.GetDomainInfo(domainName)
+	res, err := conn.GetDomainInfo(ctx, domainName)
 	if err != nil {
+		if domain.IsNotFoundError(err) {
+			d.SetId("")
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("CDN domain %s not found, removing from state", domainName),
+			})
+		}
 		return diag.FromErr(err)
 	}
 
@@ -217,6 +366,31 @@ func resourceQiniuCdnDomainRead(_ context.Context, d *schema.ResourceData, m int
 		return diag.FromErr(err)
 	}
 
+	if err := d.Set("range_origin", res.RangeOrigin); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("follow_redirect", res.FollowRedirect); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("origin_timeout", res.OriginTimeout); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// 配置中写了enabled = false的块时, 即使未开启也保留该块, 避免每次plan都出现差异
+	if err := d.Set("compress", flattenResponseDomainCompress(res.Compress, d.Get("compress").(*schema.Set).Len() > 0)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("image_slim", flattenResponseDomainImageSlim(res.ImageSlim, d.Get("image_slim").(*schema.Set).Len() > 0)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("response_headers", flattenResponseDomainResponseHeaders(res.ResponseHeaderControls)); err != nil {
+		return diag.FromErr(err)
+	}
+
 	if res.Protocol == "https" {
 		if err := d.Set("https", flattenResponseDomainHttps(res.Https)); err != nil {
 			return diag.FromErr(err)
@@ -230,6 +404,9 @@ func resourceQiniuCdnDomainCreate(ctx context.Context, d *schema.ResourceData, m
 	var diags diag.Diagnostics
 	conn := m.(Client).domainconn
 
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	defer cancel()
+
 	domainName := d.Get("name").(string)
 
 	input := domain.DomainInfo{
@@ -238,6 +415,10 @@ func resourceQiniuCdnDomainCreate(ctx context.Context, d *schema.ResourceData, m
 		GeoCover: d.Get("geo_cover").(string),
 		Protocol: d.Get("protocol").(string),
 		Source:   convertInputDomainSource(d.Get("source").(*schema.Set).List()),
+
+		RangeOrigin:    d.Get("range_origin").(bool),
+		FollowRedirect: d.Get("follow_redirect").(bool),
+		OriginTimeout:  d.Get("origin_timeout").(int),
 	}
 
 	if protocol, ok := d.GetOk("protocol"); ok {
@@ -255,29 +436,25 @@ func resourceQiniuCdnDomainCreate(ctx context.Context, d *schema.ResourceData, m
 		input.Cache = convertInputDomainCache(cache.(*schema.Set).List())
 	}
 
-	_, err := conn.CreateDomain(domainName, input)
-
-	if err != nil {
-		return diag.FromErr(err)
+	if compress, ok := d.GetOk("compress"); ok {
+		input.Compress = convertInputDomainCompress(compress.(*schema.Set).List())
 	}
 
-	err = resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
-		res, err := conn.DescribeDomain(domainName)
+	if imageSlim, ok := d.GetOk("image_slim"); ok {
+		input.ImageSlim = convertInputDomainImageSlim(imageSlim.(*schema.Set).List())
+	}
 
-		if err != nil {
-			return resource.NonRetryableError(fmt.Errorf("error describing domain: %s", err))
-		}
+	if headers, ok := d.GetOk("response_headers"); ok {
+		input.ResponseHeaderControls = convertInputDomainResponseHeaders(headers.([]interface{}))
+	}
 
-		if res.OperationType == "create_domain" && res.OperatingState == "processing" {
-			return resource.RetryableError(fmt.Errorf("domain creation is processing"))
-		}
+	_, err := conn.CreateDomain(ctx, domainName, input)
 
-		if res.OperationType == "create_domain" && res.OperatingState == "success" {
-			return nil
-		}
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
-		return resource.NonRetryableError(fmt.Errorf("error describing domain: unkown state"))
-	})
+	err = conn.WaitForOperation(ctx, domainName, domain.OperationCreateDomain)
 
 	if err != nil {
 		return diag.FromErr(err)
@@ -294,62 +471,43 @@ func resourceQiniuCdnDomainUpdate(ctx context.Context, d *schema.ResourceData, m
 	conn := m.(Client).domainconn
 	domainName := d.Id()
 
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+
+	if d.HasChange("platform") {
+		err := conn.RunOperation(ctx, domainName, domain.OperationModifyPlatform, func() error {
+			return conn.ModifyDomainPlatform(ctx, domainName, d.Get("platform").(string))
+		})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.HasChange("geo_cover") {
+		err := conn.RunOperation(ctx, domainName, domain.OperationModifyGeoCover, func() error {
+			return conn.ModifyDomainGeoCover(ctx, domainName, d.Get("geo_cover").(string))
+		})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	protocol := d.Get("protocol").(string)
 	if d.HasChange("protocol") {
 		if protocol == "http" {
 			// HTTPS降级为HTTP
-			err := conn.UnsslizeDomain(domainName)
-			if err != nil {
-				return diag.FromErr(err)
-			}
-
-			err = resource.RetryContext(ctx, d.Timeout(schema.TimeoutUpdate), func() *resource.RetryError {
-				res, err := conn.DescribeDomain(domainName)
-
-				if err != nil {
-					return resource.NonRetryableError(fmt.Errorf("[unsslize] error describing domain: %s", err))
-				}
-
-				if res.OperationType == "unsslize" && res.OperatingState == "processing" {
-					return resource.RetryableError(fmt.Errorf("domain unsslize is processing"))
-				}
-
-				if res.OperationType == "unsslize" && res.OperatingState == "success" {
-					return nil
-				}
-
-				return resource.NonRetryableError(fmt.Errorf("[unsslize] error describing domain: unkown state"))
+			err := conn.RunOperation(ctx, domainName, domain.OperationUnsslize, func() error {
+				return conn.UnsslizeDomain(ctx, domainName)
 			})
-
 			if err != nil {
 				return diag.FromErr(err)
 			}
 		} else {
 			// HTTP升级为HTTPS
 			https := convertInputDomainHttps(d.Get("https").(*schema.Set).List())
-			err := conn.SslizeDomain(domainName, https)
-			if err != nil {
-				return diag.FromErr(err)
-			}
-
-			err = resource.RetryContext(ctx, d.Timeout(schema.TimeoutUpdate), func() *resource.RetryError {
-				res, err := conn.DescribeDomain(domainName)
-
-				if err != nil {
-					return resource.NonRetryableError(fmt.Errorf("[sslize] error describing domain: %s", err))
-				}
-
-				if res.OperationType == "sslize" && res.OperatingState == "processing" {
-					return resource.RetryableError(fmt.Errorf("domain sslize is processing"))
-				}
-
-				if res.OperationType == "sslize" && res.OperatingState == "success" {
-					return nil
-				}
-
-				return resource.NonRetryableError(fmt.Errorf("[sslize] error describing domain: unkown state"))
+			err := conn.RunOperation(ctx, domainName, domain.OperationSslize, func() error {
+				return conn.SslizeDomain(ctx, domainName, https)
 			})
-
 			if err != nil {
 				return diag.FromErr(err)
 			}
@@ -359,60 +517,77 @@ func resourceQiniuCdnDomainUpdate(ctx context.Context, d *schema.ResourceData, m
 		// 执行证书更新的逻辑
 		if protocol == "https" && d.HasChange("https") {
 			https := convertInputDomainHttps(d.Get("https").(*schema.Set).List())
-			err := conn.ModifyDomainHttpsConf(domainName, https)
+			err := conn.RunOperation(ctx, domainName, domain.OperationModifyHttpsConf, func() error {
+				return conn.ModifyDomainHttpsConf(ctx, domainName, https)
+			})
 			if err != nil {
 				return diag.FromErr(err)
 			}
+		}
+	}
 
-			err = resource.RetryContext(ctx, d.Timeout(schema.TimeoutUpdate), func() *resource.RetryError {
-				res, err := conn.DescribeDomain(domainName)
-
-				if err != nil {
-					return resource.NonRetryableError(fmt.Errorf("[https] error describing domain: %s", err))
-				}
-
-				if res.OperationType == "modify_https_conf" && res.OperatingState == "processing" {
-					return resource.RetryableError(fmt.Errorf("domain https is processing"))
-				}
-
-				if res.OperationType == "modify_https_conf" && res.OperatingState == "success" {
-					return nil
-				}
-
-				return resource.NonRetryableError(fmt.Errorf("[https] error describing domain: unkown state"))
-			})
+	if d.HasChange("source") {
+		source := convertInputDomainSource(d.Get("source").(*schema.Set).List())
 
-			if err != nil {
-				return diag.FromErr(err)
-			}
+		err := conn.RunOperation(ctx, domainName, domain.OperationModifySource, func() error {
+			return conn.ModifyDomainSource(ctx, domainName, source)
+		})
+		if err != nil {
+			return diag.FromErr(err)
 		}
 	}
 
 	if d.HasChange("cache") {
 		cache := convertInputDomainCache(d.Get("cache").(*schema.Set).List())
-		err := conn.ModifyDomainCache(domainName, cache)
+		err := conn.RunOperation(ctx, domainName, domain.OperationModifyCache, func() error {
+			return conn.ModifyDomainCache(ctx, domainName, cache)
+		})
 		if err != nil {
 			return diag.FromErr(err)
 		}
+	}
 
-		err = resource.RetryContext(ctx, d.Timeout(schema.TimeoutUpdate), func() *resource.RetryError {
-			res, err := conn.DescribeDomain(domainName)
-
-			if err != nil {
-				return resource.NonRetryableError(fmt.Errorf("[cache] error describing domain: %s", err))
-			}
-
-			if res.OperationType == "modify_cache" && res.OperatingState == "processing" {
-				return resource.RetryableError(fmt.Errorf("domain cache is processing"))
-			}
+	if d.HasChanges("range_origin", "follow_redirect", "origin_timeout") {
+		originConf := domain.DomainOriginConf{
+			RangeOrigin:    d.Get("range_origin").(bool),
+			FollowRedirect: d.Get("follow_redirect").(bool),
+			OriginTimeout:  d.Get("origin_timeout").(int),
+		}
+		err := conn.RunOperation(ctx, domainName, domain.OperationModifyOriginConf, func() error {
+			return conn.ModifyDomainOriginConf(ctx, domainName, originConf)
+		})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
-			if res.OperationType == "modify_cache" && res.OperatingState == "success" {
-				return nil
-			}
+	if d.HasChange("compress") {
+		compress := convertInputDomainCompress(d.Get("compress").(*schema.Set).List())
+		err := conn.RunOperation(ctx, domainName, domain.OperationModifyCompress, func() error {
+			return conn.ModifyDomainCompress(ctx, domainName, compress)
+		})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
-			return resource.NonRetryableError(fmt.Errorf("[cache] error describing domain: unkown state"))
+	if d.HasChange("image_slim") {
+		imageSlim := convertInputDomainImageSlim(d.Get("image_slim").(*schema.Set).List())
+		err := conn.RunOperation(ctx, domainName, domain.OperationModifyImageSlim, func() error {
+			return conn.ModifyDomainImageSlim(ctx, domainName, imageSlim)
 		})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
+	if d.HasChange("response_headers") {
+		headers := domain.DomainResponseHeaderInfo{
+			ResponseHeaderControls: convertInputDomainResponseHeaders(d.Get("response_headers").([]interface{})),
+		}
+		err := conn.RunOperation(ctx, domainName, domain.OperationModifyRespHeader, func() error {
+			return conn.ModifyDomainResponseHeader(ctx, domainName, headers)
+		})
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -426,34 +601,64 @@ func resourceQiniuCdnDomainDelete(ctx context.Context, d *schema.ResourceData, m
 	conn := m.(Client).domainconn
 	domainName := d.Id()
 
-	err := conn.OfflineDomain(domainName)
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutDelete))
+	defer cancel()
+
+	res, err := conn.DescribeDomain(ctx, domainName)
 	if err != nil {
+		if domain.IsNotFoundError(err) {
+			d.SetId("")
+			return diags
+		}
 		return diag.FromErr(err)
 	}
 
-	err = conn.DeleteDomain(domainName)
+	// 域名需要先下线才能删除, 已下线的域名跳过该步骤, 正在下线的域名只需等待下线完成
+	switch {
+	case res.OperatingState == domain.OperatingStateOfflined,
+		res.OperationType == domain.OperationOfflineDomain && res.OperatingState == domain.OperatingStateSuccess:
+		// 已下线
+	case res.OperationType == domain.OperationOfflineDomain && res.OperatingState == domain.OperatingStateProcessing:
+		err = conn.WaitForOperation(ctx, domainName, domain.OperationOfflineDomain)
+	default:
+		err = conn.RunOperation(ctx, domainName, domain.OperationOfflineDomain, func() error {
+			return conn.OfflineDomain(ctx, domainName)
+		})
+	}
+
+	if err != nil {
+		if domain.IsNotFoundError(err) {
+			d.SetId("")
+			return diags
+		}
+		return diag.FromErr(err)
+	}
 
 	err = resource.RetryContext(ctx, d.Timeout(schema.TimeoutDelete), func() *resource.RetryError {
-		res, err := conn.DescribeDomain(domainName)
+		err := conn.DeleteDomain(ctx, domainName)
 
 		if err != nil {
-			if err.Error() == "无此域名" {
+			if domain.IsNotFoundError(err) {
 				return nil
 			}
-			return resource.NonRetryableError(err)
-		}
-
-		if res.OperationType == "delete_domain" && res.OperatingState == "processing" {
-			return resource.RetryableError(fmt.Errorf("domain creation is processing"))
+			if domain.IsProcessingError(err) {
+				return resource.RetryableError(err)
+			}
+			return resource.NonRetryableError(fmt.Errorf("[delete] error deleting domain: %s", err))
 		}
 
-		return resource.NonRetryableError(fmt.Errorf("error describing domain: unkown state"))
+		return nil
 	})
 
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	err = conn.WaitForDeletion(ctx, domainName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
 	d.SetId("")
 
 	return diags
@@ -483,8 +688,11 @@ func convertInputDomainHttps(hh []interface{}) domain.DomainHttpsInfo {
 
 func flattenResponseDomainSource(s domain.DomainSourceInfo) []interface{} {
 	source := map[string]interface{}{
-		"type":          s.Type,
-		"test_url_path": s.TestURLPath,
+		"type":                s.Type,
+		"test_url_path":       s.TestURLPath,
+		"host":                s.Host,
+		"follow_request_host": s.FollowHost,
+		"origin_sni":          s.SNI,
 	}
 
 	if s.Type == "qiniuBucket" {
@@ -501,9 +709,27 @@ func flattenResponseDomainSource(s domain.DomainSourceInfo) []interface{} {
 		source["domain"] = s.Domain
 	}
 
+	if s.Type == "advanced" {
+		source["advanced_source"] = flattenResponseDomainAdvancedSources(s.Advanced)
+	}
+
 	return []interface{}{source}
 }
 
+func flattenResponseDomainAdvancedSources(aa []domain.DomainAdvancedSource) []interface{} {
+	sources := make([]interface{}, len(aa))
+
+	for i, a := range aa {
+		sources[i] = map[string]interface{}{
+			"addr":   a.Addr,
+			"weight": a.Weight,
+			"backup": a.Backup,
+		}
+	}
+
+	return sources
+}
+
 func convertInputDomainSource(ss []interface{}) domain.DomainSourceInfo {
 	s := ss[0].(map[string]interface{})
 	source := domain.DomainSourceInfo{
@@ -513,11 +739,32 @@ func convertInputDomainSource(ss []interface{}) domain.DomainSourceInfo {
 		QiniuBucket: s["qiniu_bucket"].(string),
 		URLScheme:   s["url_scheme"].(string),
 		TestURLPath: s["test_url_path"].(string),
+		Host:        s["host"].(string),
+		FollowHost:  s["follow_request_host"].(bool),
+		SNI:         s["origin_sni"].(string),
+		Advanced:    convertInputDomainAdvancedSources(s["advanced_source"].([]interface{})),
 	}
 
 	return source
 }
 
+func convertInputDomainAdvancedSources(aa []interface{}) []domain.DomainAdvancedSource {
+	var sources []domain.DomainAdvancedSource
+
+	for _, a := range aa {
+		v := a.(map[string]interface{})
+		source := domain.DomainAdvancedSource{
+			Addr:   v["addr"].(string),
+			Weight: v["weight"].(int),
+			Backup: v["backup"].(bool),
+		}
+
+		sources = append(sources, source)
+	}
+
+	return sources
+}
+
 func flattenResponseDomainCache(c domain.DomainCacheInfo) []interface{} {
 	controls := make([]map[string]interface{}, len(c.CacheControls))
 
@@ -566,3 +813,90 @@ func convertInputDomainCacheControls(cc []interface{}) []domain.DomainCacheContr
 
 	return controls
 }
+
+func flattenResponseDomainResponseHeaders(hh []domain.DomainResponseHeaderControl) []interface{} {
+	headers := make([]interface{}, len(hh))
+
+	for i, h := range hh {
+		headers[i] = map[string]interface{}{
+			"key":       h.Key,
+			"value":     h.Value,
+			"operation": h.Op,
+		}
+	}
+
+	return headers
+}
+
+func convertInputDomainResponseHeaders(hh []interface{}) []domain.DomainResponseHeaderControl {
+	headers := make([]domain.DomainResponseHeaderControl, 0, len(hh))
+
+	for _, h := range hh {
+		v := h.(map[string]interface{})
+		header := domain.DomainResponseHeaderControl{
+			Key:   v["key"].(string),
+			Value: v["value"].(string),
+			Op:    v["operation"].(string),
+		}
+
+		headers = append(headers, header)
+	}
+
+	return headers
+}
+
+func flattenResponseDomainCompress(c domain.DomainCompressInfo, keepDisabled bool) []interface{} {
+	if !keepDisabled && !c.Enable && len(c.FileTypes) == 0 {
+		return []interface{}{}
+	}
+
+	compress := map[string]interface{}{
+		"enabled":    c.Enable,
+		"file_types": c.FileTypes,
+	}
+
+	return []interface{}{compress}
+}
+
+func convertInputDomainCompress(cc []interface{}) domain.DomainCompressInfo {
+	if len(cc) == 0 {
+		return domain.DomainCompressInfo{}
+	}
+
+	c := cc[0].(map[string]interface{})
+	compress := domain.DomainCompressInfo{
+		Enable:    c["enabled"].(bool),
+		FileTypes: expandStringList(c["file_types"].([]interface{})),
+	}
+
+	return compress
+}
+
+func flattenResponseDomainImageSlim(i domain.DomainImageSlimInfo, keepDisabled bool) []interface{} {
+	if !keepDisabled && !i.EnableImageSlim && len(i.PrefixImageSlims) == 0 && len(i.RegexpImageSlims) == 0 {
+		return []interface{}{}
+	}
+
+	imageSlim := map[string]interface{}{
+		"enabled":  i.EnableImageSlim,
+		"prefixes": i.PrefixImageSlims,
+		"regexes":  i.RegexpImageSlims,
+	}
+
+	return []interface{}{imageSlim}
+}
+
+func convertInputDomainImageSlim(ii []interface{}) domain.DomainImageSlimInfo {
+	if len(ii) == 0 {
+		return domain.DomainImageSlimInfo{}
+	}
+
+	i := ii[0].(map[string]interface{})
+	imageSlim := domain.DomainImageSlimInfo{
+		EnableImageSlim:  i["enabled"].(bool),
+		PrefixImageSlims: expandStringList(i["prefixes"].([]interface{})),
+		RegexpImageSlims: expandStringList(i["regexes"].([]interface{})),
+	}
+
+	return imageSlim
+}
@@ -0,0 +1,177 @@
+package qiniu
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/domain"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/qiniu/go-sdk/v7/auth"
+)
+
+type fakeResponse struct {
+	status int
+	body   string
+}
+
+// fakeDomainAPI 模拟域名查询、下线和删除接口, 域名删除后查询返回404
+type fakeDomainAPI struct {
+	mu sync.Mutex
+
+	// states 按顺序作为查询结果返回, 用完后一直返回最后一个
+	states  []domain.DomainDescriber
+	deletes []fakeResponse
+
+	describeCalls int
+	offlineCalls  int
+	deleteCalls   int
+	deleted       bool
+}
+
+func (f *fakeDomainAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodGet:
+		if f.deleted {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"error":"domain not found"}`))
+			return
+		}
+
+		i := f.describeCalls
+		if i >= len(f.states) {
+			i = len(f.states) - 1
+		}
+		f.describeCalls++
+		json.NewEncoder(w).Encode(f.states[i])
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/offline"):
+		f.offlineCalls++
+		w.Write([]byte("{}"))
+	case r.Method == http.MethodDelete:
+		i := f.deleteCalls
+		if i >= len(f.deletes) {
+			i = len(f.deletes) - 1
+		}
+		f.deleteCalls++
+
+		resp := f.deletes[i]
+		if resp.status == http.StatusOK || resp.status == http.StatusNotFound {
+			f.deleted = true
+		}
+		w.WriteHeader(resp.status)
+		w.Write([]byte(resp.body))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestResourceQiniuCdnDomainDelete(t *testing.T) {
+	minInterval, maxInterval := domain.WaitMinInterval, domain.WaitMaxInterval
+	domain.WaitMinInterval, domain.WaitMaxInterval = time.Millisecond, 5*time.Millisecond
+	defer func() {
+		domain.WaitMinInterval, domain.WaitMaxInterval = minInterval, maxInterval
+	}()
+
+	offlineProcessing := domain.DomainDescriber{OperationType: domain.OperationOfflineDomain, OperatingState: domain.OperatingStateProcessing}
+	offlineSuccess := domain.DomainDescriber{OperationType: domain.OperationOfflineDomain, OperatingState: domain.OperatingStateSuccess}
+	online := domain.DomainDescriber{OperationType: domain.OperationCreateDomain, OperatingState: domain.OperatingStateSuccess}
+	ok := fakeResponse{http.StatusOK, "{}"}
+
+	cases := []struct {
+		name        string
+		api         *fakeDomainAPI
+		wantOffline int
+		wantDeletes int
+		wantError   string
+	}{
+		{
+			name: "offline still processing",
+			api: &fakeDomainAPI{
+				states:  []domain.DomainDescriber{offlineProcessing, offlineProcessing, offlineSuccess},
+				deletes: []fakeResponse{ok},
+			},
+			wantOffline: 0,
+			wantDeletes: 1,
+		},
+		{
+			name: "delete processing then succeeds",
+			api: &fakeDomainAPI{
+				states: []domain.DomainDescriber{online, online, offlineProcessing, offlineSuccess},
+				deletes: []fakeResponse{
+					{http.StatusBadRequest, `{"code":400,"error":"domain is processing"}`},
+					ok,
+				},
+			},
+			wantOffline: 1,
+			wantDeletes: 2,
+		},
+		{
+			name: "delete not found",
+			api: &fakeDomainAPI{
+				states:  []domain.DomainDescriber{offlineSuccess},
+				deletes: []fakeResponse{{http.StatusNotFound, `{"code":404,"error":"domain not found"}`}},
+			},
+			wantOffline: 0,
+			wantDeletes: 1,
+		},
+		{
+			name: "delete error",
+			api: &fakeDomainAPI{
+				states:  []domain.DomainDescriber{offlineSuccess},
+				deletes: []fakeResponse{{http.StatusBadRequest, `{"code":400,"error":"domain is locked"}`}},
+			},
+			wantOffline: 0,
+			wantDeletes: 1,
+			wantError:   "domain is locked",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := httptest.NewServer(c.api)
+			defer server.Close()
+
+			meta := Client{
+				domainconn: domain.NewDomainManager(auth.New("ak", "sk"), server.URL, nil),
+			}
+
+			d := schema.TestResourceDataRaw(t, resourceQiniuCdnDomain().Schema, map[string]interface{}{})
+			d.SetId("cdn.example.com")
+
+			diags := resourceQiniuCdnDomainDelete(context.Background(), d, meta)
+
+			if c.wantError != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Summary, c.wantError) {
+					t.Fatalf("expected error containing %q, got %v", c.wantError, diags)
+				}
+				if d.Id() == "" {
+					t.Errorf("expected id to be kept on error")
+				}
+			} else {
+				if diags.HasError() {
+					t.Fatalf("unexpected error: %v", diags)
+				}
+				if d.Id() != "" {
+					t.Errorf("expected id to be cleared, got %q", d.Id())
+				}
+			}
+
+			if c.api.offlineCalls != c.wantOffline {
+				t.Errorf("expected %d offline calls, got %d", c.wantOffline, c.api.offlineCalls)
+			}
+			if c.api.deleteCalls != c.wantDeletes {
+				t.Errorf("expected %d delete calls, got %d", c.wantDeletes, c.api.deleteCalls)
+			}
+		})
+	}
+}
@@ -2,7 +2,18 @@ package qiniu
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/cert"
+	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/domain"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -15,11 +26,20 @@ func resourceQiniuSslCert() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: resourceQiniuSslCertCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"name", "name_prefix"},
+			},
+			// 证书名称为name_prefix加上根据证书内容计算的后缀, 便于配合create_before_destroy轮换证书
+			"name_prefix": {
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
 				ForceNew: true,
 			},
 			"pri": {
@@ -29,10 +49,11 @@ func resourceQiniuSslCert() *schema.Resource {
 				Sensitive: true,
 			},
 			"ca": {
-				Type:      schema.TypeString,
-				Required:  true,
-				ForceNew:  true,
-				Sensitive: true,
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				Sensitive:        true,
+				ValidateDiagFunc: validateCertChain,
 			},
 			"common_name": {
 				Type:     schema.TypeString,
@@ -47,17 +68,118 @@ func resourceQiniuSslCert() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"not_after": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"issuer": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			// 叶子证书DER编码的SHA-256指纹
+			"fingerprint": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 		},
 	}
 }
 
-func resourceQiniuSslCertRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+func resourceQiniuSslCertCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	// 只校验新建或内容发生变化的证书, 避免已过期的存量证书阻塞plan
+	if d.Id() != "" && !d.HasChange("ca") && !d.HasChange("pri") {
+		return nil
+	}
+
+	computed := []string{"common_name", "dns_names", "not_after", "issuer", "fingerprint"}
+
+	if !d.NewValueKnown("ca") || !d.NewValueKnown("pri") {
+		for _, key := range computed {
+			if err := d.SetNewComputed(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ca := d.Get("ca").(string)
+	pri := d.Get("pri").(string)
+
+	if _, err := tls.X509KeyPair([]byte(ca), []byte(pri)); err != nil {
+		return fmt.Errorf("invalid certificate or private key: %s", err)
+	}
+
+	chain, err := parseCertChain(ca)
+	if err != nil {
+		return err
+	}
+
+	leaf := chain[0]
+	if time.Now().After(leaf.NotAfter) {
+		return fmt.Errorf("certificate %q expired at %s", leaf.Subject.CommonName, leaf.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	values := map[string]interface{}{
+		"common_name": leaf.Subject.CommonName,
+		"dns_names":   leaf.DNSNames,
+		"not_after":   leaf.NotAfter.UTC().Format(time.RFC3339),
+		"issuer":      leaf.Issuer.String(),
+		"fingerprint": certFingerprint(leaf),
+	}
+
+	for _, key := range computed {
+		if err := d.SetNew(key, values[key]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateCertChain 在证书链无法验证到受信任的根证书时给出警告, 格式错误由CustomizeDiff报告
+func validateCertChain(v interface{}, path cty.Path) diag.Diagnostics {
+	chain, err := parseCertChain(v.(string))
+	if err != nil {
+		return nil
+	}
+
+	leaf := chain[0]
+
+	intermediates := x509.NewCertPool()
+	for _, c := range chain[1:] {
+		intermediates.AddCert(c)
+	}
+
+	if _, err := leaf.Verify(x509.VerifyOptions{Intermediates: intermediates}); err != nil {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Warning,
+				Summary:       fmt.Sprintf("Certificate chain for %q may be incomplete", leaf.Subject.CommonName),
+				Detail:        fmt.Sprintf("Clients may fail to verify the certificate: %s. Include the intermediate certificates in ca after the leaf certificate.", err),
+				AttributePath: path,
+			},
+		}
+	}
+
+	return nil
+}
+
+func resourceQiniuSslCertRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	conn := m.(Client).certconn
 
-	c, err := conn.GetCertInfo(d.Id())
+	certId := d.Id()
+
+	c, err := conn.GetCertInfo(ctx, certId)
 
 	if err != nil {
+		if cert.IsNotFoundError(err) {
+			d.SetId("")
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("SSL cert %s not found, removing from state", certId),
+			})
+		}
 		return diag.FromErr(err)
 	}
 
@@ -73,6 +195,22 @@ func resourceQiniuSslCertRead(_ context.Context, d *schema.ResourceData, m inter
 		return diag.FromErr(err)
 	}
 
+	if chain, err := parseCertChain(c.Ca); err == nil {
+		leaf := chain[0]
+
+		if err := d.Set("not_after", leaf.NotAfter.UTC().Format(time.RFC3339)); err != nil {
+			return diag.FromErr(err)
+		}
+
+		if err := d.Set("issuer", leaf.Issuer.String()); err != nil {
+			return diag.FromErr(err)
+		}
+
+		if err := d.Set("fingerprint", certFingerprint(leaf)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	if err := d.Set("pri", c.Pri); err != nil {
 		return diag.FromErr(err)
 	}
@@ -88,10 +226,18 @@ func resourceQiniuSslCertCreate(ctx context.Context, d *schema.ResourceData, m i
 	var diags diag.Diagnostics
 	conn := m.(Client).certconn
 
-	c, err := conn.CreateCert(cert.CertInfo{
-		Name: d.Get("name").(string),
-		Pri:  d.Get("pri").(string),
-		Ca:   d.Get("ca").(string),
+	pri := d.Get("pri").(string)
+	ca := d.Get("ca").(string)
+
+	name := d.Get("name").(string)
+	if prefix, ok := d.GetOk("name_prefix"); ok {
+		name = prefix.(string) + certContentSuffix(pri, ca)
+	}
+
+	c, err := conn.CreateCert(ctx, cert.CertInfo{
+		Name: name,
+		Pri:  pri,
+		Ca:   ca,
 	})
 
 	if err != nil {
@@ -105,13 +251,30 @@ func resourceQiniuSslCertCreate(ctx context.Context, d *schema.ResourceData, m i
 	return diags
 }
 
-func resourceQiniuSslCertDelete(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+func resourceQiniuSslCertDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	conn := m.(Client).certconn
 
-	err := conn.DeleteCert(d.Id())
+	err := conn.DeleteCert(ctx, d.Id())
 
 	if err != nil {
+		if cert.IsNotFoundError(err) {
+			d.SetId("")
+			return diags
+		}
+
+		// 证书仍被域名使用时无法删除, 列出这些域名以便排查
+		domains, lookupErr := findDomainsUsingCert(ctx, m.(Client).domainconn, d.Id())
+		if lookupErr == nil && len(domains) > 0 {
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("SSL cert %s is still in use", d.Id()),
+				Detail: fmt.Sprintf("The certificate is bound to the following CDN domains: %s. "+
+					"Switch their https.cert_id to another certificate (e.g. with create_before_destroy) before deleting it. API error: %s",
+					strings.Join(domains, ", "), err),
+			})
+		}
+
 		return diag.FromErr(err)
 	}
 
@@ -119,3 +282,71 @@ func resourceQiniuSslCertDelete(_ context.Context, d *schema.ResourceData, m int
 
 	return diags
 }
+
+// certContentSuffix 根据证书内容计算名称后缀, 内容不变时后缀不变
+func certContentSuffix(pri, ca string) string {
+	sum := sha256.Sum256([]byte(pri + ca))
+	return hex.EncodeToString(sum[:])[:8]
+}
+
+func findDomainsUsingCert(ctx context.Context, conn *domain.DomainManager, certId string) ([]string, error) {
+	domainInfos, err := conn.GetDomainsInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, info := range domainInfos {
+		if info.Protocol != "https" {
+			continue
+		}
+
+		// 列表接口不返回完整的https配置, 需要单独查询
+		res, err := conn.GetDomainInfo(ctx, info.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.Https.CertID == certId {
+			names = append(names, res.Name)
+		}
+	}
+
+	return names, nil
+}
+
+// parseCertChain 解析PEM格式的证书链, 第一个证书为叶子证书
+func parseCertChain(ca string) ([]*x509.Certificate, error) {
+	var chain []*x509.Certificate
+
+	rest := []byte(ca)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %s", err)
+		}
+
+		chain = append(chain, c)
+	}
+
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no PEM encoded certificate found in ca")
+	}
+
+	return chain, nil
+}
+
+func certFingerprint(c *x509.Certificate) string {
+	sum := sha256.Sum256(c.Raw)
+	return hex.EncodeToString(sum[:])
+}
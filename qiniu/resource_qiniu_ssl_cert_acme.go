@@ -0,0 +1,377 @@
+package qiniu
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/cert"
+	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/kodo"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/qiniu/go-sdk/v7/storage"
+	"golang.org/x/crypto/acme"
+)
+
+func resourceQiniuSslCertAcme() *schema.Resource {
+	return &schema.Resource{
+		ReadContext:   resourceQiniuSslCertAcmeRead,
+		CreateContext: resourceQiniuSslCertAcmeCreate,
+		UpdateContext: resourceQiniuSslCertAcmeUpdate,
+		DeleteContext: resourceQiniuSslCertAcmeDelete,
+		CustomizeDiff: resourceQiniuSslCertAcmeCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"directory_url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      "https://acme-v02.api.letsencrypt.org/directory",
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
+			// 仅用于测试环境, 如本地的Pebble服务
+			"insecure_skip_verify": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+				Default:  false,
+			},
+			"email": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"domains": {
+				Type:     schema.TypeList,
+				Required: true,
+				ForceNew: true,
+				MinItems: 1,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			// 加速域名的源站bucket, HTTP-01验证文件会上传到该bucket
+			"bucket": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			// 证书剩余有效期少于该天数时重新签发
+			"min_days_remaining": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"cert_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"certificate_pem": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"private_key_pem": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"account_key_pem": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"not_after": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+		},
+	}
+}
+
+func resourceQiniuSslCertAcmeCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !acmeCertNeedsRenewal(d.Get("not_after").(string), d.Get("min_days_remaining").(int)) {
+		return nil
+	}
+
+	for _, key := range []string{"name", "cert_id", "certificate_pem", "private_key_pem", "not_after"} {
+		if err := d.SetNewComputed(key); err != nil {
+			return err
+		}
+	}
+
+	// 续期以替换的方式进行, 配合create_before_destroy可以在加速域名切换到新证书后再删除旧证书
+	return d.ForceNew("cert_id")
+}
+
+func resourceQiniuSslCertAcmeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	conn := m.(Client).certconn
+
+	certId := d.Id()
+
+	c, err := conn.GetCertInfo(ctx, certId)
+	if err != nil {
+		if cert.IsNotFoundError(err) {
+			d.SetId("")
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("SSL cert %s not found, removing from state", certId),
+			})
+		}
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("name", c.Name); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("cert_id", c.Id); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceQiniuSslCertAcmeCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	defer cancel()
+
+	accountKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	accountKeyDer, err := x509.MarshalECPrivateKey(accountKey)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("account_key_pem", string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: accountKeyDer}))); err != nil {
+		return diag.FromErr(err)
+	}
+
+	diags := issueAcmeCert(ctx, d, m.(Client), accountKey)
+	if diags.HasError() {
+		return diags
+	}
+
+	return append(diags, resourceQiniuSslCertAcmeRead(ctx, d, m)...)
+}
+
+// resourceQiniuSslCertAcmeUpdate 仅min_days_remaining可以原地更新, 续期由CustomizeDiff标记为替换
+func resourceQiniuSslCertAcmeUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return resourceQiniuSslCertAcmeRead(ctx, d, m)
+}
+
+func resourceQiniuSslCertAcmeDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	conn := m.(Client).certconn
+
+	err := conn.DeleteCert(ctx, d.Id())
+
+	if err != nil && !cert.IsNotFoundError(err) {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	return diags
+}
+
+// issueAcmeCert 通过ACME签发证书并上传到七牛, 成功后更新d的ID和证书内容
+func issueAcmeCert(ctx context.Context, d *schema.ResourceData, c Client, accountKey *ecdsa.PrivateKey) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	httpClient := &http.Client{}
+	if d.Get("insecure_skip_verify").(bool) {
+		httpClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+
+	client := &acme.Client{
+		Key:          accountKey,
+		DirectoryURL: d.Get("directory_url").(string),
+		HTTPClient:   httpClient,
+	}
+
+	account := &acme.Account{}
+	if email := d.Get("email").(string); email != "" {
+		account.Contact = []string{"mailto:" + email}
+	}
+
+	if _, err := client.Register(ctx, account, acme.AcceptTOS); err != nil && err != acme.ErrAccountAlreadyExists {
+		return append(diags, diag.Errorf("error registering ACME account: %s", err)...)
+	}
+
+	domains := expandStringList(d.Get("domains").([]interface{}))
+
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(domains...))
+	if err != nil {
+		return append(diags, diag.Errorf("error creating ACME order: %s", err)...)
+	}
+
+	for _, url := range order.AuthzURLs {
+		diags = append(diags, acmeAuthorizeHTTP01(ctx, client, c.bucketconn, d.Get("bucket").(string), url)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	order, err = client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return append(diags, diag.Errorf("error waiting for ACME order: %s", err)...)
+	}
+
+	certKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domains[0]},
+		DNSNames: domains,
+	}, certKey)
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	ders, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return append(diags, diag.Errorf("error finalizing ACME order: %s", err)...)
+	}
+
+	if len(ders) == 0 {
+		return append(diags, diag.Errorf("ACME server returned an empty certificate chain")...)
+	}
+
+	var certPem bytes.Buffer
+	for _, der := range ders {
+		if err := pem.Encode(&certPem, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+	}
+
+	certKeyDer, err := x509.MarshalECPrivateKey(certKey)
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	keyPem := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: certKeyDer}))
+
+	leaf, err := x509.ParseCertificate(ders[0])
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	info, err := c.certconn.CreateCert(ctx, cert.CertInfo{
+		Name: domains[0] + "-" + certContentSuffix(keyPem, certPem.String()),
+		Pri:  keyPem,
+		Ca:   certPem.String(),
+	})
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	d.SetId(info.Id)
+
+	if err := d.Set("certificate_pem", certPem.String()); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	if err := d.Set("private_key_pem", keyPem); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	if err := d.Set("not_after", leaf.NotAfter.UTC().Format(time.RFC3339)); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	return diags
+}
+
+// acmeAuthorizeHTTP01 将HTTP-01验证文件上传到bucket, 等待验证通过后删除该文件
+func acmeAuthorizeHTTP01(ctx context.Context, client *acme.Client, bucketconn *kodo.BucketManager, bucket string, url string) (diags diag.Diagnostics) {
+	authz, err := client.GetAuthorization(ctx, url)
+	if err != nil {
+		return diag.Errorf("error getting ACME authorization: %s", err)
+	}
+
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+
+	var challenge *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "http-01" {
+			challenge = c
+			break
+		}
+	}
+
+	if challenge == nil {
+		return diag.Errorf("no http-01 challenge offered for %s", authz.Identifier.Value)
+	}
+
+	body, err := client.HTTP01ChallengeResponse(challenge.Token)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	key := strings.TrimPrefix(client.HTTP01ChallengePath(challenge.Token), "/")
+	policy := storage.PutPolicy{
+		Scope:           bucket + ":" + key,
+		DeleteAfterDays: 1,
+	}
+
+	err = bucketconn.Put(ctx, policy.UploadToken(bucketconn.Mac), key, strings.NewReader(body), int64(len(body)))
+	if err != nil {
+		return diag.Errorf("error uploading http-01 challenge to bucket %s: %s", bucket, err)
+	}
+
+	// 验证文件一天后会自动删除, 删除失败时仅给出警告
+	defer func() {
+		if err := bucketconn.Delete(bucket, key); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("HTTP-01 challenge file %s was not deleted from bucket %s", key, bucket),
+				Detail:   fmt.Sprintf("The file expires after one day, or delete it manually: %s", err),
+			})
+		}
+	}()
+
+	if _, err := client.Accept(ctx, challenge); err != nil {
+		return diag.Errorf("error accepting http-01 challenge for %s: %s", authz.Identifier.Value, err)
+	}
+
+	if _, err := client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return diag.Errorf("error validating http-01 challenge for %s: %s", authz.Identifier.Value, err)
+	}
+
+	return diags
+}
+
+func acmeCertNeedsRenewal(notAfter string, minDaysRemaining int) bool {
+	t, err := time.Parse(time.RFC3339, notAfter)
+	if err != nil {
+		return false
+	}
+
+	return time.Until(t) < time.Duration(minDaysRemaining)*24*time.Hour
+}
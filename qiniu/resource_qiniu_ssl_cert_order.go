@@ -0,0 +1,259 @@
+package qiniu
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/cert"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceQiniuSslCertOrder() *schema.Resource {
+	return &schema.Resource{
+		ReadContext:   resourceQiniuSslCertOrderRead,
+		CreateContext: resourceQiniuSslCertOrderCreate,
+		DeleteContext: resourceQiniuSslCertOrderDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceQiniuSslCertOrderImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"product_type": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"years": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      1,
+				ValidateFunc: validation.IntBetween(1, 2),
+			},
+			"domain": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"dns_names": {
+				Type:     schema.TypeList,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"validation_method": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      "DNS",
+				ValidateFunc: validation.StringInSlice([]string{"DNS", "FILE"}, false),
+			},
+			"contact_email": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			// 验证记录由同一配置管理时需设为false, 否则创建时会一直等待直到超时
+			"wait_for_issuance": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+				Default:  true,
+			},
+			"state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"cert_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"validations": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"domain": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"dns_record_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"dns_record_host": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"dns_record_value": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"file_path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"file_content": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+		},
+	}
+}
+
+func resourceQiniuSslCertOrderRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	conn := m.(Client).certconn
+
+	orderId := d.Id()
+
+	o, err := conn.GetOrderInfo(ctx, orderId)
+	if err != nil {
+		if cert.IsNotFoundError(err) {
+			d.SetId("")
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("SSL cert order %s not found, removing from state", orderId),
+			})
+		}
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("product_type", o.ProductShortName); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("domain", o.DomainName); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("years", o.Years); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("dns_names", o.DnsNames); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("validation_method", o.AuthMethod); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("contact_email", o.ContactEmail); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("state", o.State); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("cert_id", o.CertId); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("validations", flattenResponseOrderValidations(o.Validations)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceQiniuSslCertOrderCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conn := m.(Client).certconn
+
+	o, err := conn.CreateOrder(ctx, cert.OrderInfo{
+		ProductShortName: d.Get("product_type").(string),
+		Years:            d.Get("years").(int),
+		DomainName:       d.Get("domain").(string),
+		DnsNames:         expandStringList(d.Get("dns_names").([]interface{})),
+		AuthMethod:       d.Get("validation_method").(string),
+		ContactEmail:     d.Get("contact_email").(string),
+	})
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(o.OrderId)
+
+	if d.Get("wait_for_issuance").(bool) {
+		err = resource.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+			o, err := conn.GetOrderInfo(ctx, d.Id())
+
+			if err != nil {
+				return resource.NonRetryableError(fmt.Errorf("error describing cert order: %s", err))
+			}
+
+			switch o.State {
+			case cert.OrderStateIssued:
+				return nil
+			case cert.OrderStatePending:
+				return resource.RetryableError(fmt.Errorf("cert order is pending validation"))
+			default:
+				return resource.NonRetryableError(fmt.Errorf("cert order is %s: %s", o.State, o.StateDesc))
+			}
+		})
+
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceQiniuSslCertOrderRead(ctx, d, m)
+}
+
+// resourceQiniuSslCertOrderImport 导入时wait_for_issuance无法从接口获取, 设为默认值以免下次plan时重新下单
+func resourceQiniuSslCertOrderImport(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	if err := d.Set("wait_for_issuance", true); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceQiniuSslCertOrderDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	conn := m.(Client).certconn
+
+	// 已签发的证书无法取消, 仅从state中移除, 证书本身保留在七牛
+	if d.Get("state").(string) == cert.OrderStatePending {
+		err := conn.CancelOrder(ctx, d.Id())
+
+		if err != nil && !cert.IsNotFoundError(err) {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId("")
+
+	return diags
+}
+
+func flattenResponseOrderValidations(vv []cert.OrderValidation) []interface{} {
+	validations := make([]interface{}, len(vv))
+
+	for i, v := range vv {
+		validations[i] = map[string]interface{}{
+			"domain":           v.Domain,
+			"dns_record_type":  v.RecordType,
+			"dns_record_host":  v.RecordHost,
+			"dns_record_value": v.RecordValue,
+			"file_path":        v.FilePath,
+			"file_content":     v.FileContent,
+		}
+	}
+
+	return validations
+}
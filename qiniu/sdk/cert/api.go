@@ -3,12 +3,16 @@ package cert
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/internal/pager"
 	"github.com/qiniu/go-sdk/v7/auth"
 	"github.com/qiniu/go-sdk/v7/client"
 )
 
-var (
-	ApiHost = "https://api.qiniu.com"
+const (
+	DefaultApiHost = "https://api.qiniu.com"
 )
 
 type CertInfo struct {
@@ -18,67 +22,107 @@ type CertInfo struct {
 	DnsNames   []string `json:"dnsnames"`
 	Pri        string   `json:"pri"`
 	Ca         string   `json:"ca"`
+	NotBefore  int64    `json:"not_before,omitempty"`
+	NotAfter   int64    `json:"not_after,omitempty"`
 }
 
 type CertManager struct {
-	Client *client.Client
-	Mac    *auth.Credentials
+	Client  *client.Client
+	Mac     *auth.Credentials
+	ApiHost string
 }
 
-func NewCertManager(mac *auth.Credentials) *CertManager {
+func NewCertManager(mac *auth.Credentials, apiHost string, clt *client.Client) *CertManager {
+	if apiHost == "" {
+		apiHost = DefaultApiHost
+	}
+
+	if clt == nil {
+		clt = &client.DefaultClient
+	}
+
 	return &CertManager{
-		Client: &client.DefaultClient,
-		Mac:    mac,
+		Client:  clt,
+		Mac:     mac,
+		ApiHost: apiHost,
 	}
 }
 
-func (m *CertManager) GetCertInfo(id string) (certInfo CertInfo, err error) {
+func (m *CertManager) GetCertInfo(ctx context.Context, id string) (certInfo CertInfo, err error) {
 	type CertResponse struct {
 		Cert CertInfo `json:"cert"`
 	}
 	certResponse := CertResponse{}
-	reqURL := fmt.Sprintf("%s/sslcert/%s", ApiHost, string(id))
-	err = m.Client.CredentialedCallWithJson(context.Background(), m.Mac, auth.TokenQiniu, &certResponse, "GET", reqURL, nil, nil)
+	reqURL := fmt.Sprintf("%s/sslcert/%s", m.ApiHost, string(id))
+	err = m.Client.CredentialedCallWithJson(ctx, m.Mac, auth.TokenQiniu, &certResponse, "GET", reqURL, nil, nil)
 	certInfo = certResponse.Cert
+	err = wrapError(id, err)
 	return
 }
 
-func (m *CertManager) GetCertsInfo() (certsInfo []CertInfo, err error) {
+// ListOptions 控制分页查询的起始位置和每页数量
+type ListOptions struct {
+	Marker string
+	Limit  int
+}
+
+// DefaultListLimit 为分页查询证书列表时每页的默认数量, 也是允许的最大值
+const DefaultListLimit = 100
+
+// ListCerts 分页查询证书列表, 每获取一页调用一次fn, fn返回false时停止查询
+func (m *CertManager) ListCerts(ctx context.Context, opts ListOptions, fn func(certs []CertInfo) bool) error {
 	type Response struct {
 		Marker string     `json:"marker"`
 		Certs  []CertInfo `json:"certs"`
 	}
 
-	marker := ""
+	limit := opts.Limit
+	if limit <= 0 || limit > DefaultListLimit {
+		limit = DefaultListLimit
+	}
+
+	return pager.Each(ctx, opts.Marker, func(ctx context.Context, marker string) (string, error) {
+		query := url.Values{}
+		query.Set("marker", marker)
+		query.Set("limit", strconv.Itoa(limit))
 
-	for {
 		response := Response{}
-		reqURL := fmt.Sprintf("%s/sslcert?marker=%s&limit=100", ApiHost, marker)
-		err = m.Client.CredentialedCall(context.Background(), m.Mac, auth.TokenQiniu, &response, "GET", reqURL, nil)
-
+		reqURL := fmt.Sprintf("%s/sslcert?%s", m.ApiHost, query.Encode())
+		err := m.Client.CredentialedCall(ctx, m.Mac, auth.TokenQiniu, &response, "GET", reqURL, nil)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 
-		if response.Marker == "" {
-			break
+		if !fn(response.Certs) {
+			return "", nil
 		}
 
-		marker = response.Marker
-		certsInfo = append(certsInfo, response.Certs...)
+		return response.Marker, nil
+	})
+}
+
+func (m *CertManager) GetCertsInfo(ctx context.Context) (certsInfo []CertInfo, err error) {
+	err = m.ListCerts(ctx, ListOptions{}, func(certs []CertInfo) bool {
+		certsInfo = append(certsInfo, certs...)
+		return true
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	return certsInfo, err
+	return certsInfo, nil
 }
 
-func (m *CertManager) DeleteCert(id string) (err error) {
-	reqURL := fmt.Sprintf("%s/sslcert/%s", ApiHost, string(id))
-	err = m.Client.CredentialedCallWithJson(context.Background(), m.Mac, auth.TokenQiniu, nil, "DELETE", reqURL, nil, nil)
+func (m *CertManager) DeleteCert(ctx context.Context, id string) (err error) {
+	reqURL := fmt.Sprintf("%s/sslcert/%s", m.ApiHost, string(id))
+	err = m.Client.CredentialedCallWithJson(ctx, m.Mac, auth.TokenQiniu, nil, "DELETE", reqURL, nil, nil)
+	err = wrapError(id, err)
 	return
 }
 
-func (m *CertManager) CreateCert(body CertInfo) (certInfo CertInfo, err error) {
-	reqURL := fmt.Sprintf("%s/sslcert", ApiHost)
-	err = m.Client.CredentialedCallWithJson(context.Background(), m.Mac, auth.TokenQiniu, &certInfo, "POST", reqURL, nil, body)
+func (m *CertManager) CreateCert(ctx context.Context, body CertInfo) (certInfo CertInfo, err error) {
+	reqURL := fmt.Sprintf("%s/sslcert", m.ApiHost)
+	err = m.Client.CredentialedCallWithJson(ctx, m.Mac, auth.TokenQiniu, &certInfo, "POST", reqURL, nil, body)
 	return
 }
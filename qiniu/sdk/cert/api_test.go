@@ -0,0 +1,74 @@
+package cert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/qiniu/go-sdk/v7/auth"
+)
+
+// 分页逻辑由pager包测试, 这里只验证查询参数的拼接
+func TestListCertsQuery(t *testing.T) {
+	cases := []struct {
+		name        string
+		opts        ListOptions
+		next        map[string]string
+		wantMarkers []string
+		wantLimit   string
+	}{
+		{
+			name:        "marker escaping",
+			next:        map[string]string{"": "a&b=c d", "a&b=c d": "x?y#z/%"},
+			wantMarkers: []string{"", "a&b=c d", "x?y#z/%"},
+			wantLimit:   "100",
+		},
+		{
+			name:        "limit and start marker",
+			opts:        ListOptions{Marker: "m&1", Limit: 10},
+			wantMarkers: []string{"m&1"},
+			wantLimit:   "10",
+		},
+		{
+			name:        "limit above maximum",
+			opts:        ListOptions{Limit: 5000},
+			wantMarkers: []string{""},
+			wantLimit:   "100",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var markers []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				markers = append(markers, query.Get("marker"))
+				if limit := query.Get("limit"); limit != c.wantLimit {
+					t.Errorf("expected limit %s, got %s", c.wantLimit, limit)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"marker": c.next[query.Get("marker")],
+					"certs":  []CertInfo{},
+				})
+			}))
+			defer server.Close()
+
+			m := NewCertManager(auth.New("ak", "sk"), server.URL, nil)
+			err := m.ListCerts(context.Background(), c.opts, func(_ []CertInfo) bool {
+				return true
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(markers, c.wantMarkers) {
+				t.Errorf("expected markers %q, got %q", c.wantMarkers, markers)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package cert
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/qiniu/go-sdk/v7/client"
+)
+
+// NotFoundError 表示请求的证书不存在
+type NotFoundError struct {
+	Id  string
+	Err error
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("cert %s not found: %s", e.Id, e.Err)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// IsNotFoundError 判断err是否为证书不存在
+func IsNotFoundError(err error) bool {
+	var e *NotFoundError
+	return errors.As(err, &e)
+}
+
+// wrapError 将API返回的404错误转换为NotFoundError
+func wrapError(id string, err error) error {
+	var e *client.ErrorInfo
+	if !errors.As(err, &e) {
+		return err
+	}
+
+	if e.HttpCode() == http.StatusNotFound {
+		return &NotFoundError{Id: id, Err: err}
+	}
+
+	return err
+}
@@ -0,0 +1,59 @@
+package cert
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qiniu/go-sdk/v7/auth"
+)
+
+const (
+	OrderStatePending  = "pending"
+	OrderStateIssued   = "issued"
+	OrderStateFailed   = "failed"
+	OrderStateCanceled = "canceled"
+)
+
+// OrderValidation 为签发证书前需要完成的DNS或文件验证信息
+type OrderValidation struct {
+	Domain      string `json:"domain"`
+	RecordType  string `json:"dns_record_type,omitempty"`
+	RecordHost  string `json:"dns_host,omitempty"`
+	RecordValue string `json:"dns_value,omitempty"`
+	FilePath    string `json:"file_path,omitempty"`
+	FileContent string `json:"file_content,omitempty"`
+}
+
+type OrderInfo struct {
+	OrderId          string            `json:"orderid,omitempty"`
+	ProductShortName string            `json:"product_short_name"`
+	Years            int               `json:"years"`
+	DomainName       string            `json:"domain_name"`
+	DnsNames         []string          `json:"dns_names,omitempty"`
+	AuthMethod       string            `json:"auth_method"`
+	ContactEmail     string            `json:"contact_email,omitempty"`
+	State            string            `json:"state,omitempty"`
+	StateDesc        string            `json:"state_desc,omitempty"`
+	CertId           string            `json:"certid,omitempty"`
+	Validations      []OrderValidation `json:"auth_array,omitempty"`
+}
+
+func (m *CertManager) CreateOrder(ctx context.Context, body OrderInfo) (orderInfo OrderInfo, err error) {
+	reqURL := fmt.Sprintf("%s/sslcert/order", m.ApiHost)
+	err = m.Client.CredentialedCallWithJson(ctx, m.Mac, auth.TokenQiniu, &orderInfo, "POST", reqURL, nil, body)
+	return
+}
+
+func (m *CertManager) GetOrderInfo(ctx context.Context, id string) (orderInfo OrderInfo, err error) {
+	reqURL := fmt.Sprintf("%s/sslcert/order/%s", m.ApiHost, id)
+	err = m.Client.CredentialedCall(ctx, m.Mac, auth.TokenQiniu, &orderInfo, "GET", reqURL, nil)
+	err = wrapError(id, err)
+	return
+}
+
+func (m *CertManager) CancelOrder(ctx context.Context, id string) (err error) {
+	reqURL := fmt.Sprintf("%s/sslcert/order/%s/cancel", m.ApiHost, id)
+	err = m.Client.CredentialedCall(ctx, m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil)
+	err = wrapError(id, err)
+	return
+}
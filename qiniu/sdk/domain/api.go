@@ -3,17 +3,23 @@ package domain
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/bingtsingw/terraform-provider-qiniu/qiniu/sdk/internal/pager"
 	"github.com/qiniu/go-sdk/v7/auth"
 	"github.com/qiniu/go-sdk/v7/client"
 )
 
-var (
-	ApiHost = "https://api.qiniu.com"
+const (
+	DefaultApiHost = "https://api.qiniu.com"
 )
 
 type DomainDescriber struct {
-	OperationType  string `json:"operationType"`
-	OperatingState string `json:"operatingState"`
+	OperationType      string `json:"operationType"`
+	OperatingState     string `json:"operatingState"`
+	OperatingStateDesc string `json:"operatingStateDesc"`
+	ModifyAt           string `json:"modifyAt"`
 }
 
 type DomainCacheControl struct {
@@ -28,19 +34,23 @@ type DomainCacheInfo struct {
 	CacheControls []DomainCacheControl `json:"cacheControls,omitempty"`
 }
 
+type DomainAdvancedSource struct {
+	Addr   string `json:"addr,omitempty"`
+	Weight int    `json:"weight,omitempty"`
+	Backup bool   `json:"backup,omitempty"`
+}
+
 type DomainSourceInfo struct {
-	Type        string   `json:"sourceType,omitempty"`
-	Host        string   `json:"sourceHost,omitempty"`
-	IPs         []string `json:"sourceIPs,omitempty"`
-	Domain      string   `json:"sourceDomain,omitempty"`
-	QiniuBucket string   `json:"sourceQiniuBucket,omitempty"`
-	URLScheme   string   `json:"sourceURLScheme,omitempty"`
-	TestURLPath string   `json:"testURLPath,omitempty"`
-	Advanced    []struct {
-		Addr   string `json:"addr,omitempty"`
-		Weight int    `json:"weight,omitempty"`
-		Backup bool   `json:"backup,omitempty"`
-	} `json:"advancedSources,omitempty"`
+	Type        string                 `json:"sourceType,omitempty"`
+	Host        string                 `json:"sourceHost,omitempty"`
+	FollowHost  bool                   `json:"sourceHostFollowRequest,omitempty"`
+	SNI         string                 `json:"sourceSNI,omitempty"`
+	IPs         []string               `json:"sourceIPs,omitempty"`
+	Domain      string                 `json:"sourceDomain,omitempty"`
+	QiniuBucket string                 `json:"sourceQiniuBucket,omitempty"`
+	URLScheme   string                 `json:"sourceURLScheme,omitempty"`
+	TestURLPath string                 `json:"testURLPath,omitempty"`
+	Advanced    []DomainAdvancedSource `json:"advancedSources,omitempty"`
 }
 
 type DomainHttpsInfo struct {
@@ -49,109 +59,228 @@ type DomainHttpsInfo struct {
 	Http2Enable bool   `json:"http2Enable,omitempty"`
 }
 
+type DomainResponseHeaderControl struct {
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value,omitempty"`
+	Op    string `json:"op,omitempty"`
+}
+
+type DomainResponseHeaderInfo struct {
+	ResponseHeaderControls []DomainResponseHeaderControl `json:"responseHeaderControls"`
+}
+
+type DomainCompressInfo struct {
+	Enable    bool     `json:"enable"`
+	FileTypes []string `json:"fileTypes,omitempty"`
+}
+
+type DomainImageSlimInfo struct {
+	EnableImageSlim  bool     `json:"enableImageSlim"`
+	PrefixImageSlims []string `json:"prefixImageSlims,omitempty"`
+	RegexpImageSlims []string `json:"regexpImageSlims,omitempty"`
+}
+
+type DomainOriginConf struct {
+	RangeOrigin    bool `json:"rangeOrigin"`
+	FollowRedirect bool `json:"followRedirect"`
+	OriginTimeout  int  `json:"originTimeout,omitempty"`
+}
+
 type DomainInfo struct {
-	Name        string           `json:"name,omitempty"`
-	CName       string           `json:"cname,omitempty"`
-	Type        string           `json:"type,omitempty"`
-	Platform    string           `json:"platform,omitempty"`
-	GeoCover    string           `json:"geoCover,omitempty"`
-	Protocol    string           `json:"protocol,omitempty"`
-	TestURLPath string           `json:"testURLPath,omitempty"`
-	Source      DomainSourceInfo `json:"source,omitempty"`
-	Https       DomainHttpsInfo  `json:"https,omitempty"`
-	Cache       DomainCacheInfo  `json:"cache,omitempty"`
+	Name        string              `json:"name,omitempty"`
+	CName       string              `json:"cname,omitempty"`
+	Type        string              `json:"type,omitempty"`
+	Platform    string              `json:"platform,omitempty"`
+	GeoCover    string              `json:"geoCover,omitempty"`
+	Protocol    string              `json:"protocol,omitempty"`
+	TestURLPath string              `json:"testURLPath,omitempty"`
+	Source      DomainSourceInfo    `json:"source,omitempty"`
+	Https       DomainHttpsInfo     `json:"https,omitempty"`
+	Cache       DomainCacheInfo     `json:"cache,omitempty"`
+	Compress    DomainCompressInfo  `json:"compress,omitempty"`
+	ImageSlim   DomainImageSlimInfo `json:"imageSlim,omitempty"`
+
+	RangeOrigin    bool `json:"rangeOrigin,omitempty"`
+	FollowRedirect bool `json:"followRedirect,omitempty"`
+	OriginTimeout  int  `json:"originTimeout,omitempty"`
+
+	ResponseHeaderControls []DomainResponseHeaderControl `json:"responseHeaderControls,omitempty"`
+
+	// 列表接口不返回source, 只返回以下源站摘要
+	SourceType        string `json:"sourceType,omitempty"`
+	SourceQiniuBucket string `json:"sourceQiniuBucket,omitempty"`
 }
 
 type DomainManager struct {
-	Client *client.Client
-	Mac    *auth.Credentials
+	Client  *client.Client
+	Mac     *auth.Credentials
+	ApiHost string
 }
 
-func NewDomainManager(mac *auth.Credentials) *DomainManager {
+func NewDomainManager(mac *auth.Credentials, apiHost string, clt *client.Client) *DomainManager {
+	if apiHost == "" {
+		apiHost = DefaultApiHost
+	}
+
+	if clt == nil {
+		clt = &client.DefaultClient
+	}
+
 	return &DomainManager{
-		Client: &client.DefaultClient,
-		Mac:    mac,
+		Client:  clt,
+		Mac:     mac,
+		ApiHost: apiHost,
 	}
 }
 
-func (m *DomainManager) GetDomainsInfo() (domainInfos []DomainInfo, err error) {
+// ListOptions 控制分页查询的起始位置和每页数量
+type ListOptions struct {
+	Marker string
+	Limit  int
+}
+
+// DefaultListLimit 为分页查询域名列表时每页的默认数量, 也是接口允许的最大值
+const DefaultListLimit = 1000
+
+// ListDomains 分页查询域名列表, 每获取一页调用一次fn, fn返回false时停止查询
+func (m *DomainManager) ListDomains(ctx context.Context, opts ListOptions, fn func(domains []DomainInfo) bool) error {
 	type Response struct {
 		Marker  string       `json:"marker"`
 		Domains []DomainInfo `json:"domains"`
 	}
 
-	marker := ""
+	limit := opts.Limit
+	if limit <= 0 || limit > DefaultListLimit {
+		limit = DefaultListLimit
+	}
 
-	for {
-		response := Response{}
-		reqURL := fmt.Sprintf("%s/domain?marker=%s&limit=1000", ApiHost, marker)
-		err = m.Client.CredentialedCall(context.Background(), m.Mac, auth.TokenQiniu, &response, "GET", reqURL, nil)
+	return pager.Each(ctx, opts.Marker, func(ctx context.Context, marker string) (string, error) {
+		query := url.Values{}
+		query.Set("marker", marker)
+		query.Set("limit", strconv.Itoa(limit))
 
+		response := Response{}
+		reqURL := fmt.Sprintf("%s/domain?%s", m.ApiHost, query.Encode())
+		err := m.Client.CredentialedCall(ctx, m.Mac, auth.TokenQiniu, &response, "GET", reqURL, nil)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 
-		if response.Marker == "" {
-			break
+		if !fn(response.Domains) {
+			return "", nil
 		}
 
-		marker = response.Marker
-		domainInfos = append(domainInfos, response.Domains...)
+		return response.Marker, nil
+	})
+}
+
+func (m *DomainManager) GetDomainsInfo(ctx context.Context) (domainInfos []DomainInfo, err error) {
+	err = m.ListDomains(ctx, ListOptions{}, func(domains []DomainInfo) bool {
+		domainInfos = append(domainInfos, domains...)
+		return true
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	return domainInfos, err
+	return domainInfos, nil
+}
+
+func (m *DomainManager) GetDomainInfo(ctx context.Context, domain string) (domainInfo DomainInfo, err error) {
+	reqURL := fmt.Sprintf("%s/domain/%s", m.ApiHost, domain)
+	err = m.Client.CredentialedCall(ctx, m.Mac, auth.TokenQiniu, &domainInfo, "GET", reqURL, nil)
+	return domainInfo, wrapError(domain, err)
+}
+
+func (m *DomainManager) DescribeDomain(ctx context.Context, domain string) (response DomainDescriber, err error) {
+	reqURL := fmt.Sprintf("%s/domain/%s", m.ApiHost, domain)
+	err = m.Client.CredentialedCall(ctx, m.Mac, auth.TokenQiniu, &response, "GET", reqURL, nil)
+	return response, wrapError(domain, err)
+}
+
+func (m *DomainManager) CreateDomain(ctx context.Context, domain string, body DomainInfo) (domainInfo DomainInfo, err error) {
+	reqURL := fmt.Sprintf("%s/domain/%s", m.ApiHost, domain)
+	err = m.Client.CredentialedCallWithJson(ctx, m.Mac, auth.TokenQiniu, &domainInfo, "POST", reqURL, nil, body)
+	return domainInfo, wrapError(domain, err)
+}
+
+func (m *DomainManager) OfflineDomain(ctx context.Context, domain string) (err error) {
+	reqURL := fmt.Sprintf("%s/domain/%s/offline", m.ApiHost, domain)
+	err = m.Client.CredentialedCall(ctx, m.Mac, auth.TokenQiniu, nil, "POST", reqURL, nil)
+	return wrapError(domain, err)
+}
+
+func (m *DomainManager) DeleteDomain(ctx context.Context, domain string) (err error) {
+	reqURL := fmt.Sprintf("%s/domain/%s", m.ApiHost, domain)
+	err = m.Client.CredentialedCall(ctx, m.Mac, auth.TokenQiniu, nil, "DELETE", reqURL, nil)
+	return wrapError(domain, err)
+}
+
+func (m *DomainManager) ModifyDomainPlatform(ctx context.Context, domain string, platform string) (err error) {
+	body := map[string]string{"platform": platform}
+	reqURL := fmt.Sprintf("%s/domain/%s/platform", m.ApiHost, domain)
+	err = m.Client.CredentialedCallWithJson(ctx, m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil, body)
+	return wrapError(domain, err)
+}
+
+func (m *DomainManager) ModifyDomainGeoCover(ctx context.Context, domain string, geoCover string) (err error) {
+	body := map[string]string{"geoCover": geoCover}
+	reqURL := fmt.Sprintf("%s/domain/%s/geocover", m.ApiHost, domain)
+	err = m.Client.CredentialedCallWithJson(ctx, m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil, body)
+	return wrapError(domain, err)
 }
 
-func (m *DomainManager) GetDomainInfo(domain string) (domainInfo DomainInfo, err error) {
-	reqURL := fmt.Sprintf("%s/domain/%s", ApiHost, domain)
-	err = m.Client.CredentialedCall(context.Background(), m.Mac, auth.TokenQiniu, &domainInfo, "GET", reqURL, nil)
-	return domainInfo, err
+func (m *DomainManager) UnsslizeDomain(ctx context.Context, domain string) (err error) {
+	reqURL := fmt.Sprintf("%s/domain/%s/unsslize", m.ApiHost, domain)
+	err = m.Client.CredentialedCall(ctx, m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil)
+	return wrapError(domain, err)
 }
 
-func (m *DomainManager) DescribeDomain(domain string) (response DomainDescriber, err error) {
-	reqURL := fmt.Sprintf("%s/domain/%s", ApiHost, domain)
-	err = m.Client.CredentialedCall(context.Background(), m.Mac, auth.TokenQiniu, &response, "GET", reqURL, nil)
-	return response, err
+func (m *DomainManager) SslizeDomain(ctx context.Context, domain string, body DomainHttpsInfo) (err error) {
+	reqURL := fmt.Sprintf("%s/domain/%s/sslize", m.ApiHost, domain)
+	err = m.Client.CredentialedCallWithJson(ctx, m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil, body)
+	return wrapError(domain, err)
 }
 
-func (m *DomainManager) CreateDomain(domain string, body DomainInfo) (domainInfo DomainInfo, err error) {
-	reqURL := fmt.Sprintf("%s/domain/%s", ApiHost, domain)
-	err = m.Client.CredentialedCallWithJson(context.Background(), m.Mac, auth.TokenQiniu, &domainInfo, "POST", reqURL, nil, body)
-	return domainInfo, err
+func (m *DomainManager) ModifyDomainHttpsConf(ctx context.Context, domain string, body DomainHttpsInfo) (err error) {
+	reqURL := fmt.Sprintf("%s/domain/%s/httpsconf", m.ApiHost, domain)
+	err = m.Client.CredentialedCallWithJson(ctx, m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil, body)
+	return wrapError(domain, err)
 }
 
-func (m *DomainManager) OfflineDomain(domain string) (err error) {
-	reqURL := fmt.Sprintf("%s/domain/%s/offline", ApiHost, domain)
-	err = m.Client.CredentialedCall(context.Background(), m.Mac, auth.TokenQiniu, nil, "POST", reqURL, nil)
-	return err
+func (m *DomainManager) ModifyDomainSource(ctx context.Context, domain string, body DomainSourceInfo) (err error) {
+	reqURL := fmt.Sprintf("%s/domain/%s/source", m.ApiHost, domain)
+	err = m.Client.CredentialedCallWithJson(ctx, m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil, body)
+	return wrapError(domain, err)
 }
 
-func (m *DomainManager) DeleteDomain(domain string) (err error) {
-	reqURL := fmt.Sprintf("%s/domain/%s", ApiHost, domain)
-	err = m.Client.CredentialedCall(context.Background(), m.Mac, auth.TokenQiniu, nil, "DELETE", reqURL, nil)
-	return err
+func (m *DomainManager) ModifyDomainCache(ctx context.Context, domain string, body DomainCacheInfo) (err error) {
+	reqURL := fmt.Sprintf("%s/domain/%s/cache", m.ApiHost, domain)
+	err = m.Client.CredentialedCallWithJson(ctx, m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil, body)
+	return wrapError(domain, err)
 }
 
-func (m *DomainManager) UnsslizeDomain(domain string) (err error) {
-	reqURL := fmt.Sprintf("%s/domain/%s/unsslize", ApiHost, domain)
-	err = m.Client.CredentialedCall(context.Background(), m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil)
-	return err
+func (m *DomainManager) ModifyDomainResponseHeader(ctx context.Context, domain string, body DomainResponseHeaderInfo) (err error) {
+	reqURL := fmt.Sprintf("%s/domain/%s/responseheader", m.ApiHost, domain)
+	err = m.Client.CredentialedCallWithJson(ctx, m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil, body)
+	return wrapError(domain, err)
 }
 
-func (m *DomainManager) SslizeDomain(domain string, body DomainHttpsInfo) (err error) {
-	reqURL := fmt.Sprintf("%s/domain/%s/sslize", ApiHost, domain)
-	err = m.Client.CredentialedCallWithJson(context.Background(), m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil, body)
-	return err
+func (m *DomainManager) ModifyDomainCompress(ctx context.Context, domain string, body DomainCompressInfo) (err error) {
+	reqURL := fmt.Sprintf("%s/domain/%s/compress", m.ApiHost, domain)
+	err = m.Client.CredentialedCallWithJson(ctx, m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil, body)
+	return wrapError(domain, err)
 }
 
-func (m *DomainManager) ModifyDomainHttpsConf(domain string, body DomainHttpsInfo) (err error) {
-	reqURL := fmt.Sprintf("%s/domain/%s/httpsconf", ApiHost, domain)
-	err = m.Client.CredentialedCallWithJson(context.Background(), m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil, body)
-	return err
+func (m *DomainManager) ModifyDomainImageSlim(ctx context.Context, domain string, body DomainImageSlimInfo) (err error) {
+	reqURL := fmt.Sprintf("%s/domain/%s/imageslim", m.ApiHost, domain)
+	err = m.Client.CredentialedCallWithJson(ctx, m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil, body)
+	return wrapError(domain, err)
 }
 
-func (m *DomainManager) ModifyDomainCache(domain string, body DomainCacheInfo) (err error) {
-	reqURL := fmt.Sprintf("%s/domain/%s/cache", ApiHost, domain)
-	err = m.Client.CredentialedCallWithJson(context.Background(), m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil, body)
-	return err
+func (m *DomainManager) ModifyDomainOriginConf(ctx context.Context, domain string, body DomainOriginConf) (err error) {
+	reqURL := fmt.Sprintf("%s/domain/%s/originconf", m.ApiHost, domain)
+	err = m.Client.CredentialedCallWithJson(ctx, m.Mac, auth.TokenQiniu, nil, "PUT", reqURL, nil, body)
+	return wrapError(domain, err)
 }
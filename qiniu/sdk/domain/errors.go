@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/qiniu/go-sdk/v7/client"
+)
+
+// NotFoundError 表示请求的域名不存在
+type NotFoundError struct {
+	Domain string
+	Err    error
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("domain %s not found: %s", e.Domain, e.Err)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// IsNotFoundError 判断err是否为域名不存在
+func IsNotFoundError(err error) bool {
+	var e *NotFoundError
+	return errors.As(err, &e)
+}
+
+// IsProcessingError 判断err是否为域名有其他操作正在进行中
+func IsProcessingError(err error) bool {
+	var e *client.ErrorInfo
+	if !errors.As(err, &e) {
+		return false
+	}
+
+	return strings.Contains(strings.ToLower(e.Err), "processing")
+}
+
+// wrapError 根据HTTP状态码将API返回的404错误转换为NotFoundError
+func wrapError(domain string, err error) error {
+	var e *client.ErrorInfo
+	if !errors.As(err, &e) {
+		return err
+	}
+
+	if e.HttpCode() == http.StatusNotFound {
+		return &NotFoundError{Domain: domain, Err: err}
+	}
+
+	return err
+}
@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	OperationCreateDomain     = "create_domain"
+	OperationOfflineDomain    = "offline_domain"
+	OperationOnlineDomain     = "online_domain"
+	OperationDeleteDomain     = "delete_domain"
+	OperationSslize           = "sslize"
+	OperationUnsslize         = "unsslize"
+	OperationModifyHttpsConf  = "modify_https_conf"
+	OperationModifySource     = "modify_source"
+	OperationModifyCache      = "modify_cache"
+	OperationModifyRespHeader = "modify_response_header"
+	OperationModifyCompress   = "modify_compress"
+	OperationModifyImageSlim  = "modify_imageslim"
+	OperationModifyOriginConf = "modify_origin_conf"
+	OperationModifyPlatform   = "modify_platform"
+	OperationModifyGeoCover   = "modify_geocover"
+)
+
+const (
+	OperatingStateProcessing   = "processing"
+	OperatingStateSuccess      = "success"
+	OperatingStateFailed       = "failed"
+	OperatingStateFrozen       = "frozen"
+	OperatingStateOfflined     = "offlined"
+	OperatingStateNotIcpFrozen = "notIcpFrozen"
+)
+
+var (
+	// WaitMinInterval 和 WaitMaxInterval 控制轮询域名状态的退避间隔
+	WaitMinInterval = 2 * time.Second
+	WaitMaxInterval = 30 * time.Second
+)
+
+// OperationFailedError 表示域名操作执行失败, Reason为平台返回的失败原因
+type OperationFailedError struct {
+	Domain         string
+	OperationType  string
+	OperatingState string
+	Reason         string
+}
+
+func (e *OperationFailedError) Error() string {
+	return fmt.Sprintf("domain %s operation %s is %s: %s", e.Domain, e.OperationType, e.OperatingState, e.Reason)
+}
+
+// WaitForIdle 等待域名上正在进行的操作结束, 七牛不允许同一域名上并发执行多个操作
+func (m *DomainManager) WaitForIdle(ctx context.Context, domain string) error {
+	_, err := m.waitForIdle(ctx, domain)
+	return err
+}
+
+// waitForIdle 返回域名空闲时的状态, 用于判断之后发起的操作是否已被平台接收
+func (m *DomainManager) waitForIdle(ctx context.Context, domain string) (state DomainDescriber, err error) {
+	err = m.poll(ctx, func() (bool, error) {
+		res, err := m.DescribeDomain(ctx, domain)
+		if err != nil {
+			return false, err
+		}
+
+		state = res
+		return res.OperatingState != OperatingStateProcessing, nil
+	})
+
+	return state, err
+}
+
+// WaitForOperation 等待域名上的operationType操作执行完成
+func (m *DomainManager) WaitForOperation(ctx context.Context, domain string, operationType string) error {
+	return m.waitForOperation(ctx, domain, operationType, nil)
+}
+
+// waitForOperation 等待operationType操作执行完成, before为发起操作前的状态.
+// 上一次操作可能与本次类型相同且已成功, 因此只有经过processing或状态发生变化后才认为本次操作已完成
+func (m *DomainManager) waitForOperation(ctx context.Context, domain string, operationType string, before *DomainDescriber) error {
+	changed := before == nil
+
+	return m.poll(ctx, func() (bool, error) {
+		res, err := m.DescribeDomain(ctx, domain)
+		if err != nil {
+			return false, err
+		}
+
+		if !changed && res != *before {
+			changed = true
+		}
+
+		// 操作提交后状态可能尚未更新, 继续等待
+		if res.OperationType != operationType {
+			return false, nil
+		}
+
+		switch res.OperatingState {
+		case OperatingStateProcessing:
+			changed = true
+			return false, nil
+		case OperatingStateSuccess:
+			return changed, nil
+		case OperatingStateOfflined:
+			if operationType == OperationOfflineDomain {
+				return changed, nil
+			}
+			return false, operationFailed(domain, res)
+		default:
+			// 状态未变化时为上一次同类操作的结果, 继续等待本次操作
+			if !changed {
+				return false, nil
+			}
+			return false, operationFailed(domain, res)
+		}
+	})
+}
+
+// WaitForDeletion 等待域名删除完成, 即查询域名返回NotFoundError
+func (m *DomainManager) WaitForDeletion(ctx context.Context, domain string) error {
+	return m.poll(ctx, func() (bool, error) {
+		res, err := m.DescribeDomain(ctx, domain)
+		if err != nil {
+			if IsNotFoundError(err) {
+				return true, nil
+			}
+			return false, err
+		}
+
+		if res.OperationType == OperationDeleteDomain && res.OperatingState == OperatingStateFailed {
+			return false, operationFailed(domain, res)
+		}
+
+		return false, nil
+	})
+}
+
+func operationFailed(domain string, res DomainDescriber) error {
+	return &OperationFailedError{
+		Domain:         domain,
+		OperationType:  res.OperationType,
+		OperatingState: res.OperatingState,
+		Reason:         res.OperatingStateDesc,
+	}
+}
+
+// poll 以指数退避的间隔调用check, 直到check返回true、出错或ctx结束
+func (m *DomainManager) poll(ctx context.Context, check func() (bool, error)) error {
+	interval := WaitMinInterval
+
+	for {
+		done, err := check()
+		if err != nil {
+			return err
+		}
+
+		if done {
+			return nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > WaitMaxInterval {
+			interval = WaitMaxInterval
+		}
+	}
+}
+
+// RunOperation 等待域名上其他操作结束后调用apply发起operationType操作, 并等待其执行完成
+func (m *DomainManager) RunOperation(ctx context.Context, domain string, operationType string, apply func() error) error {
+	before, err := m.waitForIdle(ctx, domain)
+	if err != nil {
+		return err
+	}
+
+	if err := apply(); err != nil {
+		return err
+	}
+
+	return m.waitForOperation(ctx, domain, operationType, &before)
+}
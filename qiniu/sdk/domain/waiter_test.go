@@ -0,0 +1,209 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qiniu/go-sdk/v7/auth"
+)
+
+// fakeDescribeServer 按顺序返回states中的状态, 用完后一直返回最后一个
+type fakeDescribeServer struct {
+	mu       sync.Mutex
+	states   []DomainDescriber
+	describe int
+	applied  int
+}
+
+func (f *fakeDescribeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method != http.MethodGet {
+		f.applied++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+		return
+	}
+
+	i := f.describe
+	if i >= len(f.states) {
+		i = len(f.states) - 1
+	}
+	f.describe++
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(f.states[i])
+}
+
+func newTestDomainManager(t *testing.T, handler http.Handler) *DomainManager {
+	t.Helper()
+
+	minInterval, maxInterval := WaitMinInterval, WaitMaxInterval
+	WaitMinInterval, WaitMaxInterval = time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() {
+		WaitMinInterval, WaitMaxInterval = minInterval, maxInterval
+	})
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewDomainManager(auth.New("ak", "sk"), server.URL, nil)
+}
+
+func state(operationType, operatingState string) DomainDescriber {
+	return DomainDescriber{OperationType: operationType, OperatingState: operatingState}
+}
+
+func TestWaitForOperation(t *testing.T) {
+	cases := []struct {
+		name       string
+		states     []DomainDescriber
+		wantReason string
+	}{
+		{
+			name: "processing then success",
+			states: []DomainDescriber{
+				state(OperationModifySource, OperatingStateProcessing),
+				state(OperationModifySource, OperatingStateProcessing),
+				state(OperationModifySource, OperatingStateSuccess),
+			},
+		},
+		{
+			name: "operation not registered yet",
+			states: []DomainDescriber{
+				state(OperationModifyCache, OperatingStateSuccess),
+				state(OperationModifySource, OperatingStateSuccess),
+			},
+		},
+		{
+			name: "failed",
+			states: []DomainDescriber{
+				state(OperationModifySource, OperatingStateProcessing),
+				{OperationType: OperationModifySource, OperatingState: OperatingStateFailed, OperatingStateDesc: "source unreachable"},
+			},
+			wantReason: "source unreachable",
+		},
+		{
+			name: "frozen",
+			states: []DomainDescriber{
+				{OperationType: OperationModifySource, OperatingState: OperatingStateFrozen, OperatingStateDesc: "domain frozen"},
+			},
+			wantReason: "domain frozen",
+		},
+		{
+			name: "offlined is terminal for offline",
+			states: []DomainDescriber{
+				state(OperationOfflineDomain, OperatingStateProcessing),
+				state(OperationOfflineDomain, OperatingStateOfflined),
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			operationType := OperationModifySource
+			if c.states[len(c.states)-1].OperationType == OperationOfflineDomain {
+				operationType = OperationOfflineDomain
+			}
+
+			m := newTestDomainManager(t, &fakeDescribeServer{states: c.states})
+			err := m.WaitForOperation(context.Background(), "cdn.example.com", operationType)
+
+			if c.wantReason == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			var e *OperationFailedError
+			if !errors.As(err, &e) {
+				t.Fatalf("expected OperationFailedError, got %v", err)
+			}
+			if e.Reason != c.wantReason {
+				t.Errorf("expected reason %q, got %q", c.wantReason, e.Reason)
+			}
+		})
+	}
+}
+
+func TestRunOperation(t *testing.T) {
+	cases := []struct {
+		name string
+		// 调用apply前应已查询的次数
+		wantDescribesBeforeApply int
+		states                   []DomainDescriber
+	}{
+		{
+			name:                     "waits for other operation",
+			wantDescribesBeforeApply: 3,
+			states: []DomainDescriber{
+				state(OperationModifyCache, OperatingStateProcessing),
+				state(OperationModifyCache, OperatingStateProcessing),
+				state(OperationModifyCache, OperatingStateSuccess),
+				state(OperationModifySource, OperatingStateProcessing),
+				state(OperationModifySource, OperatingStateSuccess),
+			},
+		},
+		{
+			name:                     "same operation succeeded before",
+			wantDescribesBeforeApply: 1,
+			states: []DomainDescriber{
+				state(OperationModifySource, OperatingStateSuccess),
+				state(OperationModifySource, OperatingStateSuccess),
+				state(OperationModifySource, OperatingStateProcessing),
+				state(OperationModifySource, OperatingStateSuccess),
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := &fakeDescribeServer{states: c.states}
+			m := newTestDomainManager(t, server)
+
+			err := m.RunOperation(context.Background(), "cdn.example.com", OperationModifySource, func() error {
+				server.mu.Lock()
+				describes := server.describe
+				server.mu.Unlock()
+
+				if describes != c.wantDescribesBeforeApply {
+					t.Errorf("apply called after %d describes, want %d", describes, c.wantDescribesBeforeApply)
+				}
+				return m.ModifyDomainSource(context.Background(), "cdn.example.com", DomainSourceInfo{})
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			// 不能把上一次同类操作的success当作本次操作的结果
+			if server.describe != len(c.states) {
+				t.Errorf("expected %d describes, got %d", len(c.states), server.describe)
+			}
+			if server.applied != 1 {
+				t.Errorf("expected operation to be applied once, got %d", server.applied)
+			}
+		})
+	}
+}
+
+func TestWaitForOperationCanceled(t *testing.T) {
+	m := newTestDomainManager(t, &fakeDescribeServer{
+		states: []DomainDescriber{state(OperationModifySource, OperatingStateProcessing)},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := m.WaitForOperation(ctx, "cdn.example.com", OperationModifySource)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
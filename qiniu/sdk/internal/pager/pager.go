@@ -0,0 +1,37 @@
+package pager
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrRepeatedMarker 表示服务端再次返回了当前页的marker, 继续查询会陷入死循环
+var ErrRepeatedMarker = errors.New("pager: server returned the same marker again")
+
+// FetchFunc 获取marker对应的一页数据, 返回下一页的marker, 为空表示已经是最后一页
+type FetchFunc func(ctx context.Context, marker string) (next string, err error)
+
+// Each 从marker开始依次调用fetch获取每一页数据, 直到最后一页、fetch返回错误或ctx结束
+func Each(ctx context.Context, marker string, fetch FetchFunc) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		next, err := fetch(ctx, marker)
+		if err != nil {
+			return err
+		}
+
+		if next == "" {
+			return nil
+		}
+
+		// 服务端返回相同的marker时报错, 避免死循环或静默截断结果
+		if next == marker {
+			return ErrRepeatedMarker
+		}
+
+		marker = next
+	}
+}
@@ -0,0 +1,90 @@
+package pager
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEach(t *testing.T) {
+	fetchErr := errors.New("fetch failed")
+
+	cases := []struct {
+		name        string
+		start       string
+		pages       map[string]string
+		errAt       string
+		wantMarkers []string
+		wantErr     error
+	}{
+		{
+			name:        "single page",
+			pages:       map[string]string{"": ""},
+			wantMarkers: []string{""},
+		},
+		{
+			name:        "last page",
+			pages:       map[string]string{"": "m1", "m1": "m2", "m2": ""},
+			wantMarkers: []string{"", "m1", "m2"},
+		},
+		{
+			name:        "start marker",
+			start:       "m1",
+			pages:       map[string]string{"m1": "m2", "m2": ""},
+			wantMarkers: []string{"m1", "m2"},
+		},
+		{
+			name:        "repeated marker",
+			pages:       map[string]string{"": "m1", "m1": "m1"},
+			wantMarkers: []string{"", "m1"},
+			wantErr:     ErrRepeatedMarker,
+		},
+		{
+			name:        "fetch error",
+			pages:       map[string]string{"": "m1", "m1": ""},
+			errAt:       "m1",
+			wantMarkers: []string{"", "m1"},
+			wantErr:     fetchErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var markers []string
+			err := Each(context.Background(), c.start, func(_ context.Context, marker string) (string, error) {
+				markers = append(markers, marker)
+				if c.errAt != "" && marker == c.errAt {
+					return "", fetchErr
+				}
+				return c.pages[marker], nil
+			})
+
+			if !errors.Is(err, c.wantErr) {
+				t.Fatalf("expected error %v, got %v", c.wantErr, err)
+			}
+			if !reflect.DeepEqual(markers, c.wantMarkers) {
+				t.Errorf("expected markers %q, got %q", c.wantMarkers, markers)
+			}
+		})
+	}
+}
+
+func TestEachCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	pages := 0
+	err := Each(ctx, "", func(_ context.Context, marker string) (string, error) {
+		pages++
+		// 获取第一页后取消, 不应继续获取下一页
+		cancel()
+		return marker + "x", nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+	if pages != 1 {
+		t.Errorf("expected 1 page, got %d", pages)
+	}
+}
@@ -0,0 +1,55 @@
+package kodo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+
+	"github.com/qiniu/go-sdk/v7/auth"
+	"github.com/qiniu/go-sdk/v7/client"
+	"github.com/qiniu/go-sdk/v7/storage"
+)
+
+const (
+	DefaultUcHost = storage.UcHost
+)
+
+// BucketManager 在storage.BucketManager的基础上支持自定义UC服务地址和上传地址
+type BucketManager struct {
+	*storage.BucketManager
+	UcHost string
+	UpHost string
+}
+
+func NewBucketManager(mac *auth.Credentials, cfg *storage.Config, ucHost string, upHost string, clt *client.Client) *BucketManager {
+	if ucHost == "" {
+		ucHost = DefaultUcHost
+	}
+
+	return &BucketManager{
+		BucketManager: storage.NewBucketManagerEx(mac, cfg, clt),
+		UcHost:        ucHost,
+		UpHost:        upHost,
+	}
+}
+
+// Put 以表单方式上传文件, 未指定UpHost时根据bucket所在区域选择上传地址, 不受Cfg中指定的rs/rsf区域影响
+func (m *BucketManager) Put(ctx context.Context, upToken string, key string, data io.Reader, size int64) error {
+	cfg := *m.Cfg
+	cfg.Zone = nil
+
+	uploader := storage.NewFormUploaderEx(&cfg, m.Client)
+	return uploader.Put(ctx, nil, upToken, key, data, size, &storage.PutExtra{UpHost: m.UpHost})
+}
+
+func (m *BucketManager) BucketInfosInRegion(ctx context.Context, region storage.RegionID, statistics bool) (bucketInfos []storage.BucketSummary, err error) {
+	query := url.Values{}
+	query.Set("region", string(region))
+	query.Set("fs", strconv.FormatBool(statistics))
+
+	reqURL := fmt.Sprintf("%s/v2/bucketInfos?%s", m.UcHost, query.Encode())
+	err = m.Client.CredentialedCall(ctx, m.Mac, auth.TokenQiniu, &bucketInfos, "POST", reqURL, nil)
+	return bucketInfos, err
+}
@@ -0,0 +1,154 @@
+package transport
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxLoggedBodySize 为日志中输出的请求体/响应体的最大长度, 超出部分会被截断
+var MaxLoggedBodySize = 16 * 1024
+
+const redacted = "<redacted>"
+
+var (
+	// 证书接口的请求与响应中以pri字段传递私钥
+	priFieldPattern = regexp.MustCompile(`("pri"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	// 兜底处理其它位置出现的PEM格式私钥
+	privateKeyPattern = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)
+
+	sensitiveHeaders = map[string]bool{
+		"Authorization":       true,
+		"Proxy-Authorization": true,
+		"Cookie":              true,
+		"Set-Cookie":          true,
+	}
+)
+
+// LoggingTransport 以[DEBUG]级别记录每个请求的方法、URL、状态码、耗时和X-Reqid,
+// LogBodies为true时以[TRACE]级别额外记录请求头和请求体/响应体, 其中的凭证和私钥会被脱敏
+type LoggingTransport struct {
+	Base      http.RoundTripper
+	LogBodies bool
+}
+
+func NewLoggingTransport(base http.RoundTripper, logBodies bool) *LoggingTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &LoggingTransport{
+		Base:      base,
+		LogBodies: logBodies,
+	}
+}
+
+func (t *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.LogBodies {
+		body, err := peekRequestBody(req)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("[TRACE] qiniu: request %s %s\n%s\n%s", req.Method, req.URL.Redacted(), formatHeader(req.Header), redactBody(body))
+	}
+
+	start := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	latency := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		log.Printf("[DEBUG] qiniu: %s %s failed after %s: %s", req.Method, req.URL.Redacted(), latency, err)
+		return resp, err
+	}
+
+	log.Printf("[DEBUG] qiniu: %s %s %d (%s) X-Reqid: %s", req.Method, req.URL.Redacted(), resp.StatusCode, latency, resp.Header.Get("X-Reqid"))
+
+	if t.LogBodies {
+		body, err := peekResponseBody(resp)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("[TRACE] qiniu: response %s %s\n%s\n%s", req.Method, req.URL.Redacted(), formatHeader(resp.Header), redactBody(body))
+	}
+
+	return resp, nil
+}
+
+// peekRequestBody 读取请求体用于日志输出, 并保证请求体仍可被发送
+func peekRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return ioutil.ReadAll(body)
+	}
+
+	b, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	return b, nil
+}
+
+func peekResponseBody(resp *http.Response) ([]byte, error) {
+	if resp.Body == nil {
+		return nil, nil
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	return b, nil
+}
+
+func formatHeader(header http.Header) string {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		value := strings.Join(header[k], ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(k)] {
+			value = redacted
+		}
+		sb.WriteString(k + ": " + value + "\n")
+	}
+
+	return sb.String()
+}
+
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	s := priFieldPattern.ReplaceAllString(string(body), `$1"`+redacted+`"`)
+	s = privateKeyPattern.ReplaceAllString(s, redacted)
+
+	if len(s) > MaxLoggedBodySize {
+		s = s[:MaxLoggedBodySize] + "...(truncated)"
+	}
+
+	return s
+}
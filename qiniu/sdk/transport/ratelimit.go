@@ -0,0 +1,112 @@
+package transport
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter 为令牌桶限流器, 按rate个每秒的速度补充令牌, 最多积累burst个
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter 创建限流器, rate不大于0时返回nil, 表示不限流
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+
+	if burst < 1 {
+		burst = int(math.Ceil(rate))
+	}
+
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait 阻塞直到获取一个令牌或ctx结束
+func (l *RateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+
+	delay := l.reserve()
+	if delay <= 0 {
+		return 0, nil
+	}
+
+	if err := sleep(ctx, delay); err != nil {
+		// 未发出请求, 归还预占的令牌
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return 0, err
+	}
+
+	return delay, nil
+}
+
+// reserve 预占一个令牌, 令牌不足时允许余额为负, 返回需要等待的时间, 以保证先到先得
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// RateLimitTransport 按Classify返回的接口类别选择限流器, 未配置限流器的类别不限流
+type RateLimitTransport struct {
+	Base     http.RoundTripper
+	Limiters map[string]*RateLimiter
+	Classify func(req *http.Request) string
+}
+
+func NewRateLimitTransport(base http.RoundTripper, limiters map[string]*RateLimiter, classify func(req *http.Request) string) *RateLimitTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &RateLimitTransport{
+		Base:     base,
+		Limiters: limiters,
+		Classify: classify,
+	}
+}
+
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Classify != nil {
+		family := t.Classify(req)
+
+		delay, err := t.Limiters[family].Wait(req.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		if delay > 0 {
+			log.Printf("[DEBUG] %s %s delayed %s by %s rate limit", req.Method, req.URL.Redacted(), delay.Round(time.Millisecond), family)
+		}
+	}
+
+	return t.Base.RoundTrip(req)
+}
@@ -0,0 +1,159 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// StatusRateLimited 为七牛接口被限流时返回的状态码
+const StatusRateLimited = 573
+
+var (
+	DefaultMinRetryDelay = 500 * time.Millisecond
+	DefaultMaxRetryDelay = 30 * time.Second
+)
+
+// RetryTransport 在网络错误、5xx和限流时按指数退避加随机抖动重试请求
+type RetryTransport struct {
+	Base       http.RoundTripper
+	MaxRetries int
+	MinDelay   time.Duration
+	MaxDelay   time.Duration
+}
+
+func NewRetryTransport(base http.RoundTripper, maxRetries int) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &RetryTransport{
+		Base:       base,
+		MaxRetries: maxRetries,
+		MinDelay:   DefaultMinRetryDelay,
+		MaxDelay:   DefaultMaxRetryDelay,
+	}
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil {
+			// 请求体只能读取一次, 重试前需要重新获取
+			if req.GetBody == nil {
+				return nil, errors.New("retry: request body can not be replayed")
+			}
+
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.Base.RoundTrip(req)
+
+		if attempt >= t.MaxRetries || !t.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt, resp)
+
+		if err != nil {
+			log.Printf("[DEBUG] %s %s failed, retrying in %s: %s", req.Method, req.URL.Redacted(), delay, err)
+		} else {
+			log.Printf("[DEBUG] %s %s returned %d, retrying in %s", req.Method, req.URL.Redacted(), resp.StatusCode, delay)
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *RetryTransport) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		if req.Context().Err() != nil {
+			return false
+		}
+		return isIdempotent(req.Method)
+	}
+
+	switch {
+	case resp.StatusCode == StatusRateLimited || resp.StatusCode == http.StatusTooManyRequests:
+		// 限流的请求未被处理, 可以安全重试
+		return true
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		return isIdempotent(req.Method)
+	default:
+		return false
+	}
+}
+
+// backoff 优先使用Retry-After, 否则按照attempt计算带抖动的指数退避时间
+func (t *RetryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if d > t.MaxDelay {
+				return t.MaxDelay
+			}
+			return d
+		}
+	}
+
+	d := t.MinDelay << uint(attempt)
+	if d <= 0 || d > t.MaxDelay {
+		d = t.MaxDelay
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
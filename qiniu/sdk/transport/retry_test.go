@@ -0,0 +1,198 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// failingServer 对前failures个请求返回status, 之后返回200, 并记录收到的请求体
+type failingServer struct {
+	mu         sync.Mutex
+	failures   int
+	status     int
+	retryAfter string
+	requests   int
+	bodies     []string
+}
+
+func (f *failingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := ioutil.ReadAll(r.Body)
+	f.bodies = append(f.bodies, string(body))
+	f.requests++
+
+	if f.requests <= f.failures {
+		if f.retryAfter != "" {
+			w.Header().Set("Retry-After", f.retryAfter)
+		}
+		w.WriteHeader(f.status)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func newTestRetryTransport(maxRetries int) *RetryTransport {
+	t := NewRetryTransport(nil, maxRetries)
+	t.MinDelay = time.Millisecond
+	t.MaxDelay = 10 * time.Millisecond
+	return t
+}
+
+func TestRetryTransport(t *testing.T) {
+	cases := []struct {
+		name         string
+		method       string
+		body         string
+		server       *failingServer
+		maxRetries   int
+		wantStatus   int
+		wantRequests int
+	}{
+		{
+			name:         "5xx on GET is retried",
+			method:       http.MethodGet,
+			server:       &failingServer{failures: 2, status: http.StatusBadGateway},
+			maxRetries:   3,
+			wantStatus:   http.StatusOK,
+			wantRequests: 3,
+		},
+		{
+			name:         "5xx on POST is not retried",
+			method:       http.MethodPost,
+			body:         "payload",
+			server:       &failingServer{failures: 1, status: http.StatusInternalServerError},
+			maxRetries:   3,
+			wantStatus:   http.StatusInternalServerError,
+			wantRequests: 1,
+		},
+		{
+			name:         "rate limited POST is retried with body replayed",
+			method:       http.MethodPost,
+			body:         "payload",
+			server:       &failingServer{failures: 2, status: StatusRateLimited},
+			maxRetries:   3,
+			wantStatus:   http.StatusOK,
+			wantRequests: 3,
+		},
+		{
+			name:         "max retries exhausted",
+			method:       http.MethodGet,
+			server:       &failingServer{failures: 10, status: http.StatusServiceUnavailable},
+			maxRetries:   2,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantRequests: 3,
+		},
+		{
+			name:         "client errors are not retried",
+			method:       http.MethodGet,
+			server:       &failingServer{failures: 1, status: http.StatusBadRequest},
+			maxRetries:   3,
+			wantStatus:   http.StatusBadRequest,
+			wantRequests: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := httptest.NewServer(c.server)
+			defer server.Close()
+
+			req, err := http.NewRequest(c.method, server.URL, strings.NewReader(c.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := newTestRetryTransport(c.maxRetries).RoundTrip(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != c.wantStatus {
+				t.Errorf("expected status %d, got %d", c.wantStatus, resp.StatusCode)
+			}
+			if c.server.requests != c.wantRequests {
+				t.Errorf("expected %d requests, got %d", c.wantRequests, c.server.requests)
+			}
+			for i, body := range c.server.bodies {
+				if body != c.body {
+					t.Errorf("request %d: expected body %q, got %q", i, c.body, body)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportRetryAfter(t *testing.T) {
+	server := httptest.NewServer(&failingServer{failures: 1, status: StatusRateLimited, retryAfter: "1"})
+	defer server.Close()
+
+	rt := newTestRetryTransport(1)
+	rt.MaxDelay = 5 * time.Second
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+
+	start := time.Now()
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected to wait for Retry-After, retried after %s", elapsed)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestRetryTransportBodyWithoutGetBody(t *testing.T) {
+	server := httptest.NewServer(&failingServer{failures: 1, status: StatusRateLimited})
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL, ioutil.NopCloser(strings.NewReader("payload")))
+	req.GetBody = nil
+
+	_, err := newTestRetryTransport(3).RoundTrip(req)
+	if err == nil {
+		t.Fatal("expected error when body can not be replayed")
+	}
+}
+
+func TestRetryTransportCanceledWhileSleeping(t *testing.T) {
+	fake := &failingServer{failures: 10, status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	rt := newTestRetryTransport(5)
+	rt.MinDelay = time.Minute
+	rt.MaxDelay = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+	start := time.Now()
+	_, err := rt.RoundTrip(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected to stop sleeping on cancellation, took %s", elapsed)
+	}
+	if fake.requests != 1 {
+		t.Errorf("expected 1 request, got %d", fake.requests)
+	}
+}
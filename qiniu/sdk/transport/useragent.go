@@ -0,0 +1,34 @@
+package transport
+
+import (
+	"net/http"
+)
+
+// UserAgentTransport 为所有请求设置统一的User-Agent, 覆盖go-sdk默认的值
+type UserAgentTransport struct {
+	Base      http.RoundTripper
+	UserAgent string
+}
+
+func NewUserAgentTransport(base http.RoundTripper, userAgent string) *UserAgentTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &UserAgentTransport{
+		Base:      base,
+		UserAgent: userAgent,
+	}
+}
+
+func (t *UserAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.UserAgent == "" {
+		return t.Base.RoundTrip(req)
+	}
+
+	// RoundTripper不应修改传入的请求
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.UserAgent)
+
+	return t.Base.RoundTrip(req)
+}